@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/user"
+	"golang.org/x/term"
+)
+
+type command struct {
+	usage string
+	help  string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"hash-password": {
+		usage: "hash-password [password]",
+		help:  "print hash of password to be used in config file, password is read from stdin if not given",
+		run:   hashPassword,
+	},
+	"export-ipuz": {
+		usage: "export-ipuz [-contest name] game [file]",
+		help:  "write game at index game of config as ipuz to file, or to stdout if file is not given",
+		run:   exportIpuz,
+	},
+	"validate": {
+		usage: "validate [config]",
+		help:  "report every problem of config, config flag is used if config is not given",
+		run:   validate,
+	},
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [command]\n\nCommands:\n", os.Args[0])
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n    \t%s\n", commands[name].usage, commands[name].help)
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func hashPassword(args []string) error {
+	var password string
+	switch {
+	case len(args) > 1:
+		return errors.New("hash-password: too many arguments")
+	case len(args) == 1:
+		password = args[0]
+	case term.IsTerminal(int(os.Stdin.Fd())):
+		fmt.Fprint(os.Stderr, "Password: ")
+		b, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return fmt.Errorf("hash-password: %w", err)
+		}
+		password = string(b)
+	default:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("hash-password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if password == "" {
+		return errors.New("hash-password: password is empty")
+	}
+	hash, err := user.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("hash-password: %w", err)
+	}
+	fmt.Println(hash)
+	return nil
+}
+
+func exportIpuz(args []string) error {
+	fs := flag.NewFlagSet("export-ipuz", flag.ContinueOnError)
+	contest := fs.String("contest", "", "name of contest game belongs to")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("export-ipuz: %w", err)
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return errors.New("export-ipuz: expected game and optional file")
+	}
+	index, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("export-ipuz: invalid game index %s", fs.Arg(0))
+	}
+	cfg, err := config.New(*configPath)
+	if err != nil {
+		return fmt.Errorf("export-ipuz: %w", err)
+	}
+	games := cfg.Games
+	if *contest != "" {
+		games = nil
+		for _, c := range cfg.Contests {
+			if c.Name == *contest {
+				games = c.Games
+			}
+		}
+		if games == nil {
+			return fmt.Errorf("export-ipuz: contest %s not found", *contest)
+		}
+	}
+	if index < 0 || index >= len(games) {
+		return fmt.Errorf("export-ipuz: game %d not found, there are %d games", index, len(games))
+	}
+	// file is written only if the whole game could be exported
+	var out bytes.Buffer
+	if err = config.WriteIpuz(&out, games[index]); err != nil {
+		return fmt.Errorf("export-ipuz: %w", err)
+	}
+	if fs.NArg() == 2 {
+		err = os.WriteFile(fs.Arg(1), out.Bytes(), 0644)
+	} else {
+		_, err = os.Stdout.Write(out.Bytes())
+	}
+	if err != nil {
+		return fmt.Errorf("export-ipuz: %w", err)
+	}
+	return nil
+}
+
+func validate(args []string) error {
+	if len(args) > 1 {
+		return errors.New("validate: too many arguments")
+	}
+	path := *configPath
+	if len(args) == 1 {
+		path = args[0]
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+	problems := config.Validate(cfg)
+	fatal := 0
+	for _, p := range problems {
+		if config.IsFatal(p) {
+			fatal++
+			fmt.Println("error:", p)
+		} else {
+			fmt.Println("warning:", p)
+		}
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("validate: %s has %d problems, %d of them keep the server from starting",
+			path, len(problems), fatal)
+	}
+	fmt.Printf("%s is valid\n", path)
+	return nil
+}
@@ -2,8 +2,15 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/amirkhaki/crossword/key"
 	"github.com/amirkhaki/crossword/user"
@@ -16,39 +23,325 @@ type Colors struct {
 	TableEditableKeyColor lipgloss.Color `json:"table_editable_key_color"`
 	TableSelectedKeyColor lipgloss.Color `json:"table_selected_key_color"`
 	PassPhraseKeyColor    lipgloss.Color `json:"pass_phrase_key_color"`
+	TableWordKeyColor     lipgloss.Color `json:"table_word_key_color"`
+	QuestionSelectedColor lipgloss.Color `json:"question_selected_color"`
+}
+
+type Storage struct {
+	// Driver is either inmemory or sqlite
+	Driver string `json:"driver"`
+	// Path of the database file, used by sqlite
+	Path string `json:"path"`
+}
+
+type State struct {
+	// Path of the file progress of groups is saved to, progress is not
+	// saved if it is empty
+	Path string `json:"path"`
+	// Interval between saves in seconds, defaults to 5
+	Interval int `json:"interval"`
+}
+
+type Attempts struct {
+	// Cooldown in seconds a group waits after a wrong passphrase
+	Cooldown int `json:"cooldown"`
+	// Penalty in seconds added to time of a group for each wrong passphrase
+	Penalty int `json:"penalty"`
+}
+
+type Hints struct {
+	// Limit is number of hints each group may use, every revealed letter
+	// uses a hint. hints are disabled if it is zero
+	Limit int `json:"limit"`
+	// Penalty in seconds added to time of a group for each hint
+	Penalty int `json:"penalty"`
+}
+
+// Contest is a room with its own games, passphrase, users and leaderboard
+type Contest struct {
+	Name       string `json:"name"`
+	Games      []Game `json:"games"`
+	Passphrase string `json:"passphrase"`
+	// PassphraseRule derives passphrase from passphrase cells of games,
+	// Passphrase is used if it is empty
+	PassphraseRule PassphraseRule `json:"passphrase_rule"`
+	Users          []user.User    `json:"users"`
 }
 
 type Config struct {
-	Games      []Game      `json:"games"`
-	Passphrase string      `json:"passphrase"`
-	Users      []user.User `json:"users"`
-	Colors     Colors      `json:"colors"`
+	// Games, Passphrase, PassphraseRule and Users form the default
+	// contest, which has an empty name
+	Games          []Game         `json:"games"`
+	Passphrase     string         `json:"passphrase"`
+	PassphraseRule PassphraseRule `json:"passphrase_rule"`
+	Users          []user.User    `json:"users"`
+	Contests       []Contest      `json:"contests"`
+	Colors         Colors         `json:"colors"`
+	Storage        Storage        `json:"storage"`
+	// StartTime and EndTime schedule the contest, in RFC 3339 format.
+	// players wait for StartTime and every group is frozen at EndTime
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	State     State     `json:"state"`
+	Attempts  Attempts  `json:"attempts"`
+	Hints     Hints     `json:"hints"`
+}
+
+type Cell struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+type GridKey struct {
+	Row int     `json:"row"`
+	Col int     `json:"col"`
+	Key key.Key `json:"key"`
 }
 
 type Game struct {
-	Rows   int `json:"rows"`
-	Cols   int `json:"cols"`
+	// File is path of a puzzle file grid and clues are loaded from,
+	// relative to the config file. .puz, .ipuz and .grid files are
+	// supported
+	File   string `json:"file"`
+	Rows   int    `json:"rows"`
+	Cols   int    `json:"cols"`
 	Actual struct {
-		Keys []struct {
-			Row int     `json:"row"`
-			Col int     `json:"col"`
-			Key key.Key `json:"key"`
-		} `json:"keys"`
+		Keys []GridKey `json:"keys"`
 	} `json:"actual"`
 	InitialCol int      `json:"initial_col"`
 	InitialRow int      `json:"initial_row"`
 	Questions  []string `json:"questions"`
+	Clues      []Clue   `json:"clues"`
+	// PassphraseCells are marked PASSPHRASE after grid is loaded from File
+	PassphraseCells []Cell `json:"passphrase_cells"`
 }
 
-func New(path string) (cfg Config, err error) {
+func (g *Game) addKey(row, col int, k key.Key) {
+	g.Actual.Keys = append(g.Actual.Keys, GridKey{Row: row, Col: col, Key: k})
+}
+
+// load replaces grid and clues of g with the ones in g.File, dir is
+// directory of the config file
+func (g *Game) load(dir string) error {
+	if g.File == "" {
+		return nil
+	}
+	if len(g.Actual.Keys) != 0 || len(g.Clues) != 0 || len(g.Questions) != 0 {
+		return errors.New("keys, clues and questions can't be set along with file")
+	}
+	path := g.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	var loaded Game
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".puz":
+		loaded, err = LoadPuz(path)
+	case ".ipuz":
+		loaded, err = LoadIpuz(path)
+	case ".grid":
+		loaded, err = LoadGrid(path)
+	default:
+		err = fmt.Errorf("unknown puzzle format %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+	cells := make(map[Cell]int)
+	for i, k := range loaded.Actual.Keys {
+		cells[Cell{k.Row, k.Col}] = i
+	}
+	for _, c := range g.PassphraseCells {
+		i, ok := cells[c]
+		if !ok || loaded.Actual.Keys[i].Key.State == key.READONLY {
+			return fmt.Errorf("passphrase cell %d, %d is not an editable cell", c.Row, c.Col)
+		}
+		loaded.Actual.Keys[i].Key.State = key.PASSPHRASE
+	}
+	// initial cell of the config wins, unless it is a black square
+	if i, ok := cells[Cell{g.InitialRow, g.InitialCol}]; ok && loaded.Actual.Keys[i].Key.State != key.READONLY {
+		loaded.InitialRow, loaded.InitialCol = g.InitialRow, g.InitialCol
+	}
+	loaded.File = g.File
+	loaded.PassphraseCells = g.PassphraseCells
+	*g = loaded
+	return nil
+}
+
+type Direction string
+
+func (d *Direction) UnmarshalText(text []byte) error {
+	str := strings.ToLower(string(text))
+	switch Direction(str) {
+	case Across, Down:
+		*d = Direction(str)
+	default:
+		return errors.New("direction must be across or down, got " + string(text))
+	}
+	return nil
+}
+
+const (
+	Across Direction = "across"
+	Down   Direction = "down"
+)
+
+type Clue struct {
+	Number    int       `json:"number"`
+	Direction Direction `json:"direction"`
+	Row       int       `json:"row"`
+	Col       int       `json:"col"`
+	Length    int       `json:"length"`
+	Text      string    `json:"text"`
+}
+
+// Cell returns row and column of the i-th cell of the clue's answer
+func (c Clue) Cell(i int) (row, col int) {
+	if c.Direction == Down {
+		return c.Row + i, c.Col
+	}
+	return c.Row, c.Col + i
+}
+
+func (c Clue) Contains(row, col int) bool {
+	if c.Direction == Down {
+		return col == c.Col && row >= c.Row && row < c.Row+c.Length
+	}
+	return row == c.Row && col >= c.Col && col < c.Col+c.Length
+}
+
+func (c Clue) String() string {
+	return fmt.Sprintf("%d. %s (%d)", c.Number, c.Text, c.Length)
+}
+
+// NumberClues numbers a grid the standard way, scanning cells row by row
+// and numbering every open cell that starts a word of at least two cells.
+// returned clues are ordered by number, across before down, and have no text
+func NumberClues(rows, cols int, open func(row, col int) bool) (clues []Clue) {
+	isOpen := func(row, col int) bool {
+		return row >= 0 && row < rows && col >= 0 && col < cols && open(row, col)
+	}
+	number := 0
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			if !isOpen(row, col) {
+				continue
+			}
+			across := !isOpen(row, col-1) && isOpen(row, col+1)
+			down := !isOpen(row-1, col) && isOpen(row+1, col)
+			if !across && !down {
+				continue
+			}
+			number++
+			if across {
+				c := Clue{Number: number, Direction: Across, Row: row, Col: col}
+				for isOpen(c.Cell(c.Length)) {
+					c.Length++
+				}
+				clues = append(clues, c)
+			}
+			if down {
+				c := Clue{Number: number, Direction: Down, Row: row, Col: col}
+				for isOpen(c.Cell(c.Length)) {
+					c.Length++
+				}
+				clues = append(clues, c)
+			}
+		}
+	}
+	return
+}
+
+// sortClues orders clues by number, across before down
+func sortClues(clues []Clue) {
+	sort.SliceStable(clues, func(i, j int) bool {
+		if clues[i].Number != clues[j].Number {
+			return clues[i].Number < clues[j].Number
+		}
+		return clues[i].Direction == Across && clues[j].Direction == Down
+	})
+}
+
+// AllContests returns the default contest followed by cfg.Contests, the
+// default contest is left out if it has no users
+func (cfg Config) AllContests() []Contest {
+	var l []Contest
+	if len(cfg.Users) > 0 {
+		l = append(l, Contest{Games: cfg.Games, Passphrase: cfg.Passphrase,
+			PassphraseRule: cfg.PassphraseRule, Users: cfg.Users})
+	}
+	return append(l, cfg.Contests...)
+}
+
+// loadGames loads puzzle files of games
+func loadGames(dir string, games []Game) error {
+	for i := range games {
+		if err := games[i].load(dir); err != nil {
+			return fmt.Errorf("game %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Load reads config at path and the puzzle files it refers to, without
+// validating it
+func Load(path string) (cfg Config, err error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return
 	}
+	defer f.Close()
 	data, err := io.ReadAll(f)
 	if err != nil {
 		return
 	}
 	err = json.Unmarshal(data, &cfg)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(path)
+	if err = loadGames(dir, cfg.Games); err != nil {
+		return
+	}
+	for j := range cfg.Users {
+		cfg.Users[j].Group.Contest = ""
+	}
+	for i := range cfg.Contests {
+		c := &cfg.Contests[i]
+		if err = loadGames(dir, c.Games); err != nil {
+			err = fmt.Errorf("contest %s: %w", c.Name, err)
+			return
+		}
+		for j := range c.Users {
+			c.Users[j].Group.Contest = c.Name
+		}
+	}
+	return
+}
+
+// New loads config at path and rejects it if Validate finds a problem that
+// would crash the server or trap players, other problems are logged
+func New(path string) (cfg Config, err error) {
+	cfg, err = Load(path)
+	if err != nil {
+		return
+	}
+	var fatal []error
+	for _, p := range Validate(cfg) {
+		if IsFatal(p) {
+			fatal = append(fatal, p)
+		} else {
+			log.Printf("warning: %v", p)
+		}
+	}
+	switch len(fatal) {
+	case 0:
+	case 1:
+		err = fatal[0]
+	default:
+		err = fmt.Errorf("%w, and %d more problems, run validate command to see all of them",
+			fatal[0], len(fatal)-1)
+	}
 	return
 }
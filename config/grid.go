@@ -0,0 +1,188 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+// grid files draw the puzzle as lines of letters, one line per row:
+//
+//	; lines starting with ; are comments
+//	CAT
+//	A#O
+//	BEe
+//
+//	across
+//	1. Feline
+//	3. Buzzer
+//
+//	down
+//	1. Taxi
+//	2. Foot digit
+//
+// upper case letters are editable cells, lower case letters are passphrase
+// cells and # is a readonly cell. the grid ends at the first blank line and
+// is numbered the standard way, every word of it must have a clue
+const (
+	gridBlock   = '#'
+	gridComment = ';'
+)
+
+// gridParser keeps position of the line being parsed for error messages
+type gridParser struct {
+	name string
+	line int
+}
+
+func (p gridParser) errorf(col int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d:%d: %s", p.name, p.line, col, fmt.Sprintf(format, args...))
+}
+
+// ReadGrid parses a grid file, name is used in error messages
+func ReadGrid(r io.Reader, name string) (g Game, err error) {
+	p := gridParser{name: name}
+	scanner := bufio.NewScanner(r)
+	var grid [][]rune
+	var dir Direction
+	var clues map[string]*Clue
+	// lineOf keeps line of each clue section header, to report missing clues
+	lineOf := make(map[Direction]int)
+	seen := make(map[string]bool)
+	for scanner.Scan() {
+		p.line++
+		line := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace)
+		if strings.HasPrefix(strings.TrimSpace(line), string(gridComment)) {
+			continue
+		}
+		if clues == nil {
+			if line == "" {
+				if len(grid) != 0 {
+					g = buildGrid(grid)
+					clues = make(map[string]*Clue)
+					for i := range g.Clues {
+						c := &g.Clues[i]
+						clues[fmt.Sprintf("%d %s", c.Number, c.Direction)] = c
+					}
+				}
+				continue
+			}
+			row := []rune(line)
+			if len(grid) != 0 && len(row) != len(grid[0]) {
+				col := len(row)
+				if col > len(grid[0]) {
+					col = len(grid[0])
+				}
+				return g, p.errorf(col+1, "row has %d cells, first row has %d", len(row), len(grid[0]))
+			}
+			for i, c := range row {
+				if c != gridBlock && !('A' <= c && c <= 'Z') && !('a' <= c && c <= 'z') {
+					return g, p.errorf(i+1, "invalid cell %q, expected a letter or %c", c, gridBlock)
+				}
+			}
+			grid = append(grid, row)
+			continue
+		}
+		text := strings.TrimSpace(line)
+		if text == "" {
+			continue
+		}
+		indent := len([]rune(line)) - len([]rune(strings.TrimLeftFunc(line, unicode.IsSpace)))
+		switch d := Direction(strings.ToLower(strings.TrimSuffix(text, ":"))); d {
+		case Across, Down:
+			dir = d
+			lineOf[d] = p.line
+			continue
+		}
+		if dir == "" {
+			return g, p.errorf(indent+1, "clue before across or down header")
+		}
+		// only ascii digits, so that digits is both a byte and a rune count
+		digits := strings.IndexFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+		if digits == 0 {
+			return g, p.errorf(indent+1, "clue must start with its number")
+		}
+		if digits < 0 {
+			digits = len(text)
+		}
+		number, err := strconv.Atoi(text[:digits])
+		if err != nil {
+			return g, p.errorf(indent+1, "invalid clue number %s", text[:digits])
+		}
+		rest := strings.TrimPrefix(text[digits:], ".")
+		id := fmt.Sprintf("%d %s", number, dir)
+		c, ok := clues[id]
+		if !ok {
+			return g, p.errorf(indent+1, "no %s word starts at cell %d", dir, number)
+		}
+		if seen[id] {
+			return g, p.errorf(indent+1, "duplicate clue %s", id)
+		}
+		seen[id] = true
+		c.Text = strings.TrimSpace(rest)
+		if c.Text == "" {
+			return g, p.errorf(indent+digits+1, "clue %s has no text", id)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if clues == nil {
+		if len(grid) == 0 {
+			return g, p.errorf(1, "file has no grid")
+		}
+		g = buildGrid(grid)
+	}
+	for _, c := range g.Clues {
+		id := fmt.Sprintf("%d %s", c.Number, c.Direction)
+		if !seen[id] {
+			if l := lineOf[c.Direction]; l != 0 {
+				p.line = l
+			}
+			return g, p.errorf(1, "missing clue %s", id)
+		}
+	}
+	return
+}
+
+// buildGrid converts rows of a grid to a game
+func buildGrid(grid [][]rune) (g Game) {
+	g.Rows, g.Cols = len(grid), len(grid[0])
+	open := func(row, col int) bool {
+		return grid[row][col] != gridBlock
+	}
+	for row, line := range grid {
+		for col, c := range line {
+			if !open(row, col) {
+				g.addKey(row, col, key.Key{Char: key.EMPTY, MustBe: key.EMPTY, State: key.READONLY})
+				continue
+			}
+			k := key.Key{Char: key.EMPTY, MustBe: key.Letters[unicode.ToUpper(c)], State: key.EDITABLE}
+			if unicode.IsLower(c) {
+				k.State = key.PASSPHRASE
+			}
+			g.addKey(row, col, k)
+		}
+	}
+	g.Clues = NumberClues(g.Rows, g.Cols, open)
+	if len(g.Clues) != 0 {
+		g.InitialRow, g.InitialCol = g.Clues[0].Row, g.Clues[0].Col
+	}
+	return
+}
+
+// LoadGrid reads the grid file at path
+func LoadGrid(path string) (Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Game{}, err
+	}
+	defer f.Close()
+	return ReadGrid(f, path)
+}
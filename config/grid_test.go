@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+func TestReadGrid(t *testing.T) {
+	g, err := ReadGrid(strings.NewReader("; a comment\n"+catGrid), "cat.grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Rows != 3 || g.Cols != 3 || len(g.Actual.Keys) != 9 {
+		t.Fatalf("got %dx%d grid with %d keys", g.Rows, g.Cols, len(g.Actual.Keys))
+	}
+	for _, k := range g.Actual.Keys {
+		want := key.Key{Char: key.EMPTY, MustBe: key.Letters[rune("CATAxOBEE"[k.Row*3+k.Col])], State: key.EDITABLE}
+		switch {
+		case k.Row == 1 && k.Col == 1:
+			want = key.Key{Char: key.EMPTY, MustBe: key.EMPTY, State: key.READONLY}
+		case k.Row == 2 && k.Col == 2:
+			want.State = key.PASSPHRASE
+		}
+		if k.Key != want {
+			t.Errorf("cell %d, %d = %+v, want %+v", k.Row, k.Col, k.Key, want)
+		}
+	}
+	wantClues := []Clue{
+		{Number: 1, Direction: Across, Row: 0, Col: 0, Length: 3, Text: "Feline"},
+		{Number: 1, Direction: Down, Row: 0, Col: 0, Length: 3, Text: "Taxi"},
+		{Number: 2, Direction: Down, Row: 0, Col: 2, Length: 3, Text: "Foot digit"},
+		{Number: 3, Direction: Across, Row: 2, Col: 0, Length: 3, Text: "Buzzer"},
+	}
+	if !reflect.DeepEqual(g.Clues, wantClues) {
+		t.Errorf("clues = %+v, want %+v", g.Clues, wantClues)
+	}
+}
+
+func TestReadGridErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "only comments", file: "; nothing\n", want: "cat.grid:1:1: file has no grid"},
+		{name: "short row", file: "CAT\nA#\n", want: "cat.grid:2:3: row has 2 cells, first row has 3"},
+		{name: "long row", file: "CAT\nA#OX\n", want: "cat.grid:2:4: row has 4 cells, first row has 3"},
+		{name: "invalid cell", file: "CAT\nA.O\n", want: `cat.grid:2:2: invalid cell '.', expected a letter or #`},
+		{name: "clue before header", file: "AB\n\n  1. Two\n", want: "cat.grid:3:3: clue before across or down header"},
+		{name: "clue without number", file: "AB\n\nacross\nTwo\n", want: "cat.grid:4:1: clue must start with its number"},
+		{name: "persian digits", file: "AB\n\nacross\n۱. Two\n", want: "cat.grid:4:1: clue must start with its number"},
+		{name: "huge number", file: "AB\n\nacross\n99999999999999999999. Two\n", want: "cat.grid:4:1: invalid clue number 99999999999999999999"},
+		{name: "unknown clue", file: "AB\n\ndown\n1. Two\n", want: "cat.grid:4:1: no down word starts at cell 1"},
+		{name: "duplicate clue", file: "AB\n\nacross\n1. Two\n1. Again\n", want: "cat.grid:5:1: duplicate clue 1 across"},
+		{name: "clue without text", file: "AB\n\nacross\n 1.\n", want: "cat.grid:4:3: clue 1 across has no text"},
+		{name: "missing clue", file: catGrid[:strings.Index(catGrid, "2.")], want: "cat.grid:9:1: missing clue 2 down"},
+		{name: "no clues at all", file: "AB\n", want: "cat.grid:1:1: missing clue 1 across"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadGrid(strings.NewReader(tt.file), "cat.grid")
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ReadGrid() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+const (
+	ipuzVersion = "http://ipuz.org/v2"
+	ipuzKind    = "http://ipuz.org/crossword#1"
+	ipuzBlock   = "#"
+	// ipuzCircle is the style of PASSPHRASE cells
+	ipuzCircle = "circle"
+)
+
+type ipuz struct {
+	Version    string   `json:"version"`
+	Kind       []string `json:"kind"`
+	Dimensions struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"dimensions"`
+	Puzzle   [][]ipuzCell          `json:"puzzle"`
+	Solution [][]ipuzCell          `json:"solution"`
+	Clues    map[string][]ipuzClue `json:"clues"`
+	Block    string                `json:"block,omitempty"`
+	Empty    *ipuzCell             `json:"empty,omitempty"`
+}
+
+// ipuzCell is a cell of puzzle or solution of an ipuz file, numbers and
+// strings are both kept in label
+type ipuzCell struct {
+	omitted bool
+	label   string
+	circled bool
+}
+
+func (c *ipuzCell) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case string(b) == "null":
+		c.omitted = true
+		return nil
+	case len(b) != 0 && b[0] == '{':
+		var obj struct {
+			Cell  json.RawMessage `json:"cell"`
+			Value json.RawMessage `json:"value"`
+			Style json.RawMessage `json:"style"`
+		}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return err
+		}
+		var style struct {
+			Shapebg string `json:"shapebg"`
+		}
+		// style may also be name of a style, which is ignored
+		if json.Unmarshal(obj.Style, &style) == nil && style.Shapebg == ipuzCircle {
+			c.circled = true
+		}
+		inner := obj.Cell
+		if inner == nil {
+			inner = obj.Value
+		}
+		if inner == nil {
+			return nil
+		}
+		circled := c.circled
+		err := c.UnmarshalJSON(inner)
+		c.circled = circled
+		return err
+	}
+	if err := json.Unmarshal(b, &c.label); err == nil {
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("invalid cell %s", b)
+	}
+	c.label = n.String()
+	return nil
+}
+
+func (c ipuzCell) MarshalJSON() ([]byte, error) {
+	if c.omitted {
+		return []byte("null"), nil
+	}
+	var v interface{} = c.label
+	if n, err := strconv.Atoi(c.label); err == nil {
+		v = n
+	}
+	if c.circled {
+		v = map[string]interface{}{"cell": v, "style": map[string]string{"shapebg": ipuzCircle}}
+	}
+	return json.Marshal(v)
+}
+
+// ipuzClue is a clue in [number, text] or {"number": ..., "clue": ...} form
+type ipuzClue struct {
+	number ipuzCell
+	text   string
+}
+
+func (c *ipuzClue) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) != 0 && b[0] == '[' {
+		var l []json.RawMessage
+		if err := json.Unmarshal(b, &l); err != nil {
+			return err
+		}
+		if len(l) < 2 {
+			return fmt.Errorf("invalid clue %s", b)
+		}
+		if err := c.number.UnmarshalJSON(l[0]); err != nil {
+			return err
+		}
+		return json.Unmarshal(l[1], &c.text)
+	}
+	var obj struct {
+		Number *ipuzCell `json:"number"`
+		Clue   string    `json:"clue"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil || obj.Number == nil {
+		return fmt.Errorf("clue %s has no number", b)
+	}
+	c.number, c.text = *obj.Number, obj.Clue
+	return nil
+}
+
+func (c ipuzClue) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{c.number, c.text})
+}
+
+// ReadIpuz converts an ipuz crossword to a game, blocks and omitted cells
+// become READONLY keys, solution letters are stored in MustBe and circled
+// cells become PASSPHRASE keys
+func ReadIpuz(r io.Reader) (g Game, err error) {
+	var p ipuz
+	if err = json.NewDecoder(r).Decode(&p); err != nil {
+		return g, fmt.Errorf("ReadIpuz: %w", err)
+	}
+	crossword := false
+	for _, k := range p.Kind {
+		if strings.HasPrefix(k, "http://ipuz.org/crossword") {
+			crossword = true
+		}
+	}
+	if !crossword {
+		return g, errors.New("ReadIpuz: not a crossword")
+	}
+	g.Rows, g.Cols = p.Dimensions.Height, p.Dimensions.Width
+	if len(p.Puzzle) != g.Rows || len(p.Solution) != g.Rows {
+		return g, errors.New("ReadIpuz: puzzle and solution must have a row for each row of dimensions")
+	}
+	block, empty := ipuzBlock, "0"
+	if p.Block != "" {
+		block = p.Block
+	}
+	if p.Empty != nil {
+		empty = p.Empty.label
+	}
+	numbers := make(map[string]Cell)
+	open := func(row, col int) bool {
+		c := p.Puzzle[row][col]
+		return !c.omitted && c.label != block
+	}
+	for row := 0; row < g.Rows; row++ {
+		if len(p.Puzzle[row]) != g.Cols || len(p.Solution[row]) != g.Cols {
+			return g, fmt.Errorf("ReadIpuz: row %d must have %d cells", row, g.Cols)
+		}
+		for col := 0; col < g.Cols; col++ {
+			if !open(row, col) {
+				g.addKey(row, col, key.Key{Char: key.EMPTY, MustBe: key.EMPTY, State: key.READONLY})
+				continue
+			}
+			c := p.Puzzle[row][col]
+			if c.label != empty && c.label != "" {
+				numbers[c.label] = Cell{row, col}
+			}
+			s := []rune(p.Solution[row][col].label)
+			if len(s) != 1 || s[0] == ' ' {
+				return g, fmt.Errorf("ReadIpuz: cell %d, %d: unsupported solution %q", row, col, string(s))
+			}
+			must, ok := key.Letters[unicode.ToUpper(s[0])]
+			if !ok {
+				return g, fmt.Errorf("ReadIpuz: cell %d, %d: unsupported solution %q", row, col, string(s))
+			}
+			k := key.Key{Char: key.EMPTY, MustBe: must, State: key.EDITABLE}
+			if c.circled {
+				k.State = key.PASSPHRASE
+			}
+			g.addKey(row, col, k)
+		}
+	}
+	for name, clues := range p.Clues {
+		// direction may be followed by its display name, like Across:Across
+		dir := Direction(strings.ToLower(strings.SplitN(name, ":", 2)[0]))
+		if dir != Across && dir != Down {
+			return g, fmt.Errorf("ReadIpuz: unsupported clue direction %s", name)
+		}
+		for _, c := range clues {
+			cell, ok := numbers[c.number.label]
+			if !ok {
+				return g, fmt.Errorf("ReadIpuz: clue %s %s is not numbered in puzzle", c.number.label, dir)
+			}
+			number, err := strconv.Atoi(c.number.label)
+			if err != nil {
+				return g, fmt.Errorf("ReadIpuz: clue number %s is not a number", c.number.label)
+			}
+			clue := Clue{Number: number, Direction: dir, Row: cell.Row, Col: cell.Col, Text: c.text}
+			for {
+				row, col := clue.Cell(clue.Length)
+				if row >= g.Rows || col >= g.Cols || !open(row, col) {
+					break
+				}
+				clue.Length++
+			}
+			g.Clues = append(g.Clues, clue)
+		}
+	}
+	sortClues(g.Clues)
+	if len(g.Clues) != 0 {
+		g.InitialRow, g.InitialCol = g.Clues[0].Row, g.Clues[0].Col
+	}
+	return
+}
+
+// LoadIpuz reads the ipuz file at path
+func LoadIpuz(path string) (Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Game{}, err
+	}
+	defer f.Close()
+	return ReadIpuz(f)
+}
+
+// WriteIpuz writes g as an ipuz crossword. games without clues are
+// numbered the standard way and exported with empty clue texts, unless they
+// have questions, which can't be told apart by number and direction
+func WriteIpuz(w io.Writer, g Game) error {
+	if len(g.Clues) == 0 && len(g.Questions) != 0 {
+		return errors.New("WriteIpuz: game has questions instead of clues, they would be lost")
+	}
+	var p ipuz
+	p.Version = ipuzVersion
+	p.Kind = []string{ipuzKind}
+	p.Dimensions.Width, p.Dimensions.Height = g.Cols, g.Rows
+	p.Block = ipuzBlock
+	p.Empty = &ipuzCell{label: "0"}
+	p.Puzzle = make([][]ipuzCell, g.Rows)
+	p.Solution = make([][]ipuzCell, g.Rows)
+	for i := 0; i < g.Rows; i++ {
+		p.Puzzle[i] = make([]ipuzCell, g.Cols)
+		p.Solution[i] = make([]ipuzCell, g.Cols)
+		for j := 0; j < g.Cols; j++ {
+			p.Puzzle[i][j].omitted = true
+			p.Solution[i][j].omitted = true
+		}
+	}
+	for _, k := range g.Actual.Keys {
+		if k.Row < 0 || k.Row >= g.Rows || k.Col < 0 || k.Col >= g.Cols {
+			return fmt.Errorf("WriteIpuz: key %d, %d is out of grid", k.Row, k.Col)
+		}
+		if k.Key.State == key.READONLY {
+			p.Puzzle[k.Row][k.Col] = ipuzCell{label: ipuzBlock}
+			p.Solution[k.Row][k.Col] = ipuzCell{label: ipuzBlock}
+			continue
+		}
+		p.Puzzle[k.Row][k.Col] = ipuzCell{label: "0", circled: k.Key.State == key.PASSPHRASE}
+		p.Solution[k.Row][k.Col] = ipuzCell{label: string(rune(k.Key.MustBe))}
+	}
+	clues := g.Clues
+	if len(clues) == 0 {
+		clues = NumberClues(g.Rows, g.Cols, func(row, col int) bool {
+			c := p.Puzzle[row][col]
+			return !c.omitted && c.label != ipuzBlock
+		})
+	}
+	p.Clues = make(map[string][]ipuzClue)
+	for _, c := range clues {
+		if c.Row < 0 || c.Row >= g.Rows || c.Col < 0 || c.Col >= g.Cols {
+			return fmt.Errorf("WriteIpuz: clue %d %s is out of grid", c.Number, c.Direction)
+		}
+		number := strconv.Itoa(c.Number)
+		p.Puzzle[c.Row][c.Col].label = number
+		name := "Across"
+		if c.Direction == Down {
+			name = "Down"
+		}
+		p.Clues[name] = append(p.Clues[name], ipuzClue{number: ipuzCell{label: number}, text: c.Text})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return fmt.Errorf("WriteIpuz: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+// catGrid is the example of grid.go
+const catGrid = `CAT
+A#O
+BEe
+
+across
+1. Feline
+3. Buzzer
+
+down
+1. Taxi
+2. Foot digit
+`
+
+// catIpuz is catGrid with the passphrase cell circled
+const catIpuz = `{
+	"version": "http://ipuz.org/v2",
+	"kind": ["http://ipuz.org/crossword#1"],
+	"dimensions": {"width": 3, "height": 3},
+	"puzzle": [[1, 0, 2], [0, "#", 0], [3, 0, {"cell": 0, "style": {"shapebg": "circle"}}]],
+	"solution": [["C", "A", "T"], ["A", "#", "O"], ["B", "E", "E"]],
+	"clues": {
+		"Across:Across": [[1, "Feline"], {"number": 3, "clue": "Buzzer"}],
+		"Down": [[1, "Taxi"], [2, "Foot digit"]]
+	}
+}`
+
+func TestReadIpuz(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{name: "valid", file: catIpuz},
+		{name: "omitted cells", file: strings.Replace(catIpuz, `"#", 0], [3`, `null, 0], [3`, 1)},
+		{name: "invalid json", file: "{", wantErr: true},
+		{name: "not a crossword", file: strings.Replace(catIpuz, "crossword#1", "sudoku#1", 1), wantErr: true},
+		{name: "missing row", file: strings.Replace(catIpuz, `"height": 3`, `"height": 4`, 1), wantErr: true},
+		{name: "short row", file: strings.Replace(catIpuz, `["A", "#", "O"]`, `["A", "#"]`, 1), wantErr: true},
+		{name: "unsupported solution", file: strings.Replace(catIpuz, `"O"`, `"?"`, 1), wantErr: true},
+		{name: "rebus solution", file: strings.Replace(catIpuz, `"O"`, `"OX"`, 1), wantErr: true},
+		{name: "unnumbered clue", file: strings.Replace(catIpuz, `[2, "Foot digit"]`, `[4, "Foot digit"]`, 1), wantErr: true},
+		{name: "unsupported direction", file: strings.Replace(catIpuz, `"Down"`, `"Diagonal"`, 1), wantErr: true},
+		{name: "clue without number", file: strings.Replace(catIpuz, `{"number": 3, "clue": "Buzzer"}`, `{"clue": "Buzzer"}`, 1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ReadIpuz(strings.NewReader(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadIpuz() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			want, err := ReadGrid(strings.NewReader(catGrid), "cat.grid")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(g, want) {
+				t.Errorf("ReadIpuz() = %+v, want %+v", g, want)
+			}
+		})
+	}
+}
+
+func TestIpuzRoundTrip(t *testing.T) {
+	withoutClues := func() Game {
+		g, _ := ReadGrid(strings.NewReader(catGrid), "cat.grid")
+		for i := range g.Clues {
+			g.Clues[i].Text = ""
+		}
+		return g
+	}
+	tests := []struct {
+		name string
+		game func() Game
+	}{
+		{name: "read from ipuz", game: func() Game {
+			g, _ := ReadIpuz(strings.NewReader(catIpuz))
+			return g
+		}},
+		{name: "clues without text", game: withoutClues},
+		{name: "numbered on export", game: func() Game {
+			g := withoutClues()
+			g.Clues = nil
+			return g
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteIpuz(&b, tt.game()); err != nil {
+				t.Fatal(err)
+			}
+			g, err := ReadIpuz(&b)
+			if err != nil {
+				t.Fatalf("ReadIpuz() of exported game: %v", err)
+			}
+			want := tt.game()
+			if want.Clues == nil {
+				want = withoutClues()
+			}
+			if !reflect.DeepEqual(g, want) {
+				t.Errorf("round trip = %+v, want %+v", g, want)
+			}
+		})
+	}
+}
+
+func TestWriteIpuzErrors(t *testing.T) {
+	grid := func() Game {
+		g, _ := ReadGrid(strings.NewReader(catGrid), "cat.grid")
+		return g
+	}
+	tests := []struct {
+		name string
+		game func() Game
+	}{
+		{name: "questions instead of clues", game: func() Game {
+			g := grid()
+			g.Clues = nil
+			g.Questions = []string{"Feline", "Taxi"}
+			return g
+		}},
+		{name: "key out of grid", game: func() Game {
+			g := grid()
+			g.addKey(3, 0, key.Key{Char: key.EMPTY, MustBe: key.Letters['A'], State: key.EDITABLE})
+			return g
+		}},
+		{name: "clue out of grid", game: func() Game {
+			g := grid()
+			g.Clues = append(g.Clues, Clue{Number: 4, Direction: Across, Row: 5, Col: 0, Length: 1})
+			return g
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteIpuz(&b, tt.game()); err == nil {
+				t.Errorf("WriteIpuz() succeeded, wrote %s", b.String())
+			}
+		})
+	}
+}
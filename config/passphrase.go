@@ -0,0 +1,124 @@
+package config
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+// PassphraseRule tells where the expected passphrase comes from and how
+// answers are compared with it
+type PassphraseRule string
+
+const (
+	// PassphraseManual expects the hand written passphrase of config
+	PassphraseManual PassphraseRule = ""
+	// PassphraseRows reads letters of passphrase cells game by game, row by
+	// row
+	PassphraseRows PassphraseRule = "rows"
+	// PassphraseCols reads letters of passphrase cells game by game, column
+	// by column
+	PassphraseCols PassphraseRule = "cols"
+	// PassphraseAnagram accepts any arrangement of letters of passphrase
+	// cells
+	PassphraseAnagram PassphraseRule = "anagram"
+)
+
+func (r *PassphraseRule) UnmarshalText(text []byte) error {
+	rule := PassphraseRule(strings.ToLower(string(text)))
+	switch rule {
+	case PassphraseManual, PassphraseRows, PassphraseCols, PassphraseAnagram:
+		*r = rule
+	default:
+		return errors.New("passphrase rule must be rows, cols or anagram, got " + string(text))
+	}
+	return nil
+}
+
+// Passphrase is what groups must answer after solving every game
+type Passphrase struct {
+	Rule PassphraseRule
+	Text string
+}
+
+// letters returns lower case letters and digits of s, dropping spaces and
+// punctuation
+func letters(s string) []rune {
+	var l []rune
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			l = append(l, r)
+		}
+	}
+	return l
+}
+
+// Check reports whether answer is correct, case is ignored and derived
+// passphrases also ignore everything but letters and digits. nothing is
+// correct for a derived passphrase without letters
+func (p Passphrase) Check(answer string) bool {
+	if p.Rule != PassphraseManual && len(letters(p.Text)) == 0 {
+		return false
+	}
+	switch p.Rule {
+	case PassphraseRows, PassphraseCols:
+		return string(letters(answer)) == string(letters(p.Text))
+	case PassphraseAnagram:
+		a, b := letters(answer), letters(p.Text)
+		sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+		sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+		return string(a) == string(b)
+	}
+	return strings.ToLower(answer) == strings.ToLower(p.Text)
+}
+
+// DerivePassphrase returns letters of passphrase cells of games in the
+// order of rule, anagrams use the order of rows
+func DerivePassphrase(games []Game, rule PassphraseRule) string {
+	var b strings.Builder
+	for _, g := range games {
+		cells := make(map[Cell]key.Key)
+		for _, k := range g.Actual.Keys {
+			cells[Cell{k.Row, k.Col}] = k.Key
+		}
+		outer, inner := g.Rows, g.Cols
+		if rule == PassphraseCols {
+			outer, inner = g.Cols, g.Rows
+		}
+		for i := 0; i < outer; i++ {
+			for j := 0; j < inner; j++ {
+				cell := Cell{i, j}
+				if rule == PassphraseCols {
+					cell = Cell{j, i}
+				}
+				if k, ok := cells[cell]; ok && k.State == key.PASSPHRASE {
+					b.WriteRune(rune(k.MustBe))
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// ExpectedPassphrase returns the passphrase groups of c must answer
+func (c Contest) ExpectedPassphrase() Passphrase {
+	if c.PassphraseRule == PassphraseManual {
+		return Passphrase{Text: c.Passphrase}
+	}
+	return Passphrase{Rule: c.PassphraseRule, Text: DerivePassphrase(c.Games, c.PassphraseRule)}
+}
+
+// PassphraseDisagrees reports whether hand written passphrase of c doesn't
+// match letters of passphrase cells. without a rule the passphrase only has
+// to be an anagram of them
+func (c Contest) PassphraseDisagrees() (derived string, disagrees bool) {
+	rule := c.PassphraseRule
+	if rule == PassphraseManual {
+		rule = PassphraseAnagram
+	}
+	p := Passphrase{Rule: rule, Text: DerivePassphrase(c.Games, rule)}
+	return p.Text, c.Passphrase != "" && p.Text != "" && !p.Check(c.Passphrase)
+}
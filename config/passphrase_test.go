@@ -0,0 +1,108 @@
+package config
+
+import "testing"
+
+// passphraseGames have passphrase cells A, B, C in the first game and F in
+// the second
+func passphraseGames() []Game {
+	return []Game{gridGame("ab", "cD"), gridGame("Ef")}
+}
+
+func TestDerivePassphrase(t *testing.T) {
+	tests := []struct {
+		rule PassphraseRule
+		want string
+	}{
+		{rule: PassphraseRows, want: "ABCF"},
+		{rule: PassphraseCols, want: "ACBF"},
+		{rule: PassphraseAnagram, want: "ABCF"},
+	}
+	for _, tt := range tests {
+		if got := DerivePassphrase(passphraseGames(), tt.rule); got != tt.want {
+			t.Errorf("DerivePassphrase(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestPassphraseCheck(t *testing.T) {
+	tests := []struct {
+		passphrase Passphrase
+		answer     string
+		want       bool
+	}{
+		{passphrase: Passphrase{Text: "Open Sesame"}, answer: "open sesame", want: true},
+		{passphrase: Passphrase{Text: "Open Sesame"}, answer: "opensesame"},
+		{passphrase: Passphrase{Rule: PassphraseRows, Text: "ABCF"}, answer: "a b-c, f", want: true},
+		{passphrase: Passphrase{Rule: PassphraseRows, Text: "ABCF"}, answer: "acbf"},
+		{passphrase: Passphrase{Rule: PassphraseCols, Text: "ACBF"}, answer: "ACBF", want: true},
+		{passphrase: Passphrase{Rule: PassphraseAnagram, Text: "ABCF"}, answer: "Fab C", want: true},
+		{passphrase: Passphrase{Rule: PassphraseAnagram, Text: "ABCF"}, answer: "fabcc"},
+		{passphrase: Passphrase{Rule: PassphraseAnagram, Text: "ABCF"}, answer: "abc"},
+		{passphrase: Passphrase{Rule: PassphraseRows, Text: "R2D2"}, answer: "r2-d2", want: true},
+		{passphrase: Passphrase{Rule: PassphraseRows, Text: "R2D2"}, answer: "rd"},
+		{passphrase: Passphrase{Rule: PassphraseRows}, answer: ""},
+		{passphrase: Passphrase{Rule: PassphraseAnagram}, answer: "-"},
+	}
+	for _, tt := range tests {
+		if got := tt.passphrase.Check(tt.answer); got != tt.want {
+			t.Errorf("%+v.Check(%q) = %v, want %v", tt.passphrase, tt.answer, got, tt.want)
+		}
+	}
+}
+
+func TestPassphraseDisagrees(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       PassphraseRule
+		passphrase string
+		want       bool
+	}{
+		{name: "no passphrase", rule: PassphraseManual},
+		{name: "anagram of cells", rule: PassphraseManual, passphrase: "Cab F"},
+		{name: "other letters", rule: PassphraseManual, passphrase: "cabs", want: true},
+		{name: "order of rows", rule: PassphraseRows, passphrase: "abcf"},
+		{name: "order of cols", rule: PassphraseRows, passphrase: "acbf", want: true},
+	}
+	for _, tt := range tests {
+		c := Contest{Games: passphraseGames(), Passphrase: tt.passphrase, PassphraseRule: tt.rule}
+		if _, got := c.PassphraseDisagrees(); got != tt.want {
+			t.Errorf("%s: PassphraseDisagrees() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedPassphrase(t *testing.T) {
+	tests := []struct {
+		rule PassphraseRule
+		want Passphrase
+	}{
+		{rule: PassphraseManual, want: Passphrase{Text: "fabc"}},
+		{rule: PassphraseCols, want: Passphrase{Rule: PassphraseCols, Text: "ACBF"}},
+	}
+	for _, tt := range tests {
+		c := Contest{Games: passphraseGames(), Passphrase: "fabc", PassphraseRule: tt.rule}
+		if got := c.ExpectedPassphrase(); got != tt.want {
+			t.Errorf("ExpectedPassphrase() with rule %q = %+v, want %+v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestPassphraseRuleUnmarshalText(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    PassphraseRule
+		wantErr bool
+	}{
+		{text: "", want: PassphraseManual},
+		{text: "Rows", want: PassphraseRows},
+		{text: "anagram", want: PassphraseAnagram},
+		{text: "diagonal", wantErr: true},
+	}
+	for _, tt := range tests {
+		var r PassphraseRule
+		err := r.UnmarshalText([]byte(tt.text))
+		if (err != nil) != tt.wantErr || r != tt.want {
+			t.Errorf("UnmarshalText(%q) = %q, %v, want %q", tt.text, r, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+// layout of the header of Across Lite .puz files
+const (
+	puzMagic       = "ACROSS&DOWN\x00"
+	puzMagicOffset = 0x02
+	puzCIBChecksum = 0x0E
+	puzWidth       = 0x2C
+	puzHeight      = 0x2D
+	puzClueCount   = 0x2E
+	puzScrambled   = 0x32
+	puzHeaderSize  = 0x34
+	puzBlack       = '.'
+)
+
+// puzChecksum is the checksum used by .puz files
+func puzChecksum(data []byte, cksum uint16) uint16 {
+	for _, b := range data {
+		if cksum&1 != 0 {
+			cksum = cksum>>1 + 0x8000
+		} else {
+			cksum >>= 1
+		}
+		cksum += uint16(b)
+	}
+	return cksum
+}
+
+// ReadPuz converts an Across Lite .puz file to a game, black squares become
+// READONLY keys and the solution is stored in MustBe of the other keys
+func ReadPuz(r io.Reader) (g Game, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	if len(data) < puzHeaderSize || string(data[puzMagicOffset:puzMagicOffset+len(puzMagic)]) != puzMagic {
+		return g, errors.New("ReadPuz: not a .puz file")
+	}
+	header := data[:puzHeaderSize]
+	if puzChecksum(header[puzWidth:puzHeaderSize], 0) != binary.LittleEndian.Uint16(header[puzCIBChecksum:]) {
+		return g, errors.New("ReadPuz: header checksum mismatch")
+	}
+	if binary.LittleEndian.Uint16(header[puzScrambled:]) != 0 {
+		return g, errors.New("ReadPuz: scrambled puzzles are not supported")
+	}
+	g.Cols = int(header[puzWidth])
+	g.Rows = int(header[puzHeight])
+	clueCount := int(binary.LittleEndian.Uint16(header[puzClueCount:]))
+	size := g.Rows * g.Cols
+	body := data[puzHeaderSize:]
+	// solution is followed by the player's grid, which is ignored
+	if len(body) < 2*size {
+		return g, errors.New("ReadPuz: file is truncated")
+	}
+	solution := body[:size]
+	body = body[2*size:]
+
+	open := func(row, col int) bool {
+		return solution[row*g.Cols+col] != puzBlack
+	}
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			k := key.Key{Char: key.EMPTY, MustBe: key.EMPTY, State: key.READONLY}
+			if open(row, col) {
+				c := rune(solution[row*g.Cols+col])
+				must, ok := key.Letters[unicode.ToUpper(c)]
+				if !ok || c == ' ' {
+					return g, fmt.Errorf("ReadPuz: cell %d, %d: unsupported solution %q", row, col, c)
+				}
+				k = key.Key{Char: key.EMPTY, MustBe: must, State: key.EDITABLE}
+			}
+			g.addKey(row, col, k)
+		}
+	}
+
+	// strings are NUL terminated and latin-1 encoded: title, author,
+	// copyright, clues and notes
+	readString := func() (string, error) {
+		i := bytes.IndexByte(body, 0)
+		if i < 0 {
+			return "", errors.New("ReadPuz: file is truncated")
+		}
+		runes := make([]rune, i)
+		for j, b := range body[:i] {
+			runes[j] = rune(b)
+		}
+		body = body[i+1:]
+		return string(runes), nil
+	}
+	for i := 0; i < 3; i++ {
+		if _, err = readString(); err != nil {
+			return
+		}
+	}
+	g.Clues = NumberClues(g.Rows, g.Cols, open)
+	if len(g.Clues) != clueCount {
+		return g, fmt.Errorf("ReadPuz: grid has %d clues, file has %d", len(g.Clues), clueCount)
+	}
+	for i := range g.Clues {
+		if g.Clues[i].Text, err = readString(); err != nil {
+			return
+		}
+	}
+	if len(g.Clues) != 0 {
+		g.InitialRow, g.InitialCol = g.Clues[0].Row, g.Clues[0].Col
+	}
+	return
+}
+
+// LoadPuz reads the .puz file at path
+func LoadPuz(path string) (Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Game{}, err
+	}
+	defer f.Close()
+	return ReadPuz(f)
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+// puzFile builds a .puz file with the given solution, rows are joined
+// without separators and black squares are dots
+func puzFile(width, height int, solution string, clues []string) []byte {
+	header := make([]byte, puzHeaderSize)
+	copy(header[puzMagicOffset:], puzMagic)
+	header[puzWidth] = byte(width)
+	header[puzHeight] = byte(height)
+	binary.LittleEndian.PutUint16(header[puzClueCount:], uint16(len(clues)))
+	binary.LittleEndian.PutUint16(header[puzCIBChecksum:], puzChecksum(header[puzWidth:puzHeaderSize], 0))
+	var b bytes.Buffer
+	b.Write(header)
+	b.WriteString(solution)
+	b.Write(bytes.Map(func(r rune) rune {
+		if r == puzBlack {
+			return r
+		}
+		return '-'
+	}, []byte(solution)))
+	for _, s := range append([]string{"title", "author", "copyright"}, clues...) {
+		b.WriteString(s)
+		b.WriteByte(0)
+	}
+	b.WriteString("notes")
+	b.WriteByte(0)
+	return b.Bytes()
+}
+
+func TestReadPuz(t *testing.T) {
+	// CAT
+	// A.O
+	// BEE
+	valid := puzFile(3, 3, "CATA.OBEE", []string{"Feline", "Taxi", "Foot digit", "Buzzer"})
+	tests := []struct {
+		name    string
+		file    []byte
+		wantErr bool
+	}{
+		{name: "valid", file: valid},
+		{name: "not a puz file", file: []byte("ACROSS"), wantErr: true},
+		{name: "wrong magic", file: append([]byte("xxACROSS&DOWM\x00"), valid[14:]...), wantErr: true},
+		{name: "header checksum mismatch", file: func() []byte {
+			b := append([]byte(nil), valid...)
+			b[puzWidth] = 4
+			return b
+		}(), wantErr: true},
+		{name: "scrambled", file: func() []byte {
+			b := append([]byte(nil), valid...)
+			binary.LittleEndian.PutUint16(b[puzScrambled:], 4)
+			binary.LittleEndian.PutUint16(b[puzCIBChecksum:], puzChecksum(b[puzWidth:puzHeaderSize], 0))
+			return b
+		}(), wantErr: true},
+		{name: "truncated grid", file: valid[:puzHeaderSize+10], wantErr: true},
+		{name: "truncated clues", file: valid[:len(valid)-20], wantErr: true},
+		{name: "clue count mismatch", file: puzFile(3, 3, "CATA.OBEE", []string{"Feline", "Taxi", "Foot digit"}), wantErr: true},
+		{name: "unsupported solution", file: puzFile(3, 3, "CA?A.OBEE", []string{"a", "b", "c", "d"}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ReadPuz(bytes.NewReader(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadPuz() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if g.Rows != 3 || g.Cols != 3 || len(g.Actual.Keys) != 9 {
+				t.Fatalf("got %dx%d grid with %d keys", g.Rows, g.Cols, len(g.Actual.Keys))
+			}
+			for _, k := range g.Actual.Keys {
+				want := key.Key{Char: key.EMPTY, MustBe: key.Letters[rune("CATA.OBEE"[k.Row*3+k.Col])], State: key.EDITABLE}
+				if k.Row == 1 && k.Col == 1 {
+					want = key.Key{Char: key.EMPTY, MustBe: key.EMPTY, State: key.READONLY}
+				}
+				if k.Key != want {
+					t.Errorf("cell %d, %d = %+v, want %+v", k.Row, k.Col, k.Key, want)
+				}
+			}
+			wantClues := []Clue{
+				{Number: 1, Direction: Across, Row: 0, Col: 0, Length: 3, Text: "Feline"},
+				{Number: 1, Direction: Down, Row: 0, Col: 0, Length: 3, Text: "Taxi"},
+				{Number: 2, Direction: Down, Row: 0, Col: 2, Length: 3, Text: "Foot digit"},
+				{Number: 3, Direction: Across, Row: 2, Col: 0, Length: 3, Text: "Buzzer"},
+			}
+			if !reflect.DeepEqual(g.Clues, wantClues) {
+				t.Errorf("clues = %+v, want %+v", g.Clues, wantClues)
+			}
+			if g.InitialRow != 0 || g.InitialCol != 0 {
+				t.Errorf("initial cell = %d, %d, want 0, 0", g.InitialRow, g.InitialCol)
+			}
+		})
+	}
+}
+
+func TestNumberClues(t *testing.T) {
+	tests := []struct {
+		name string
+		grid []string
+		want []Clue
+	}{
+		{name: "single cells are not words", grid: []string{"A.", ".B"}},
+		{name: "one word", grid: []string{"AB"}, want: []Clue{
+			{Number: 1, Direction: Across, Length: 2},
+		}},
+		{name: "crossing words", grid: []string{".A.", "BCD", ".E."}, want: []Clue{
+			{Number: 1, Direction: Down, Row: 0, Col: 1, Length: 3},
+			{Number: 2, Direction: Across, Row: 1, Col: 0, Length: 3},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NumberClues(len(tt.grid), len(tt.grid[0]), func(row, col int) bool {
+				return tt.grid[row][col] != '.'
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NumberClues() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
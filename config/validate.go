@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/amirkhaki/crossword/key"
+)
+
+// fatalError is a problem that would crash the server or trap players
+type fatalError struct {
+	error
+}
+
+func fatalf(format string, args ...interface{}) error {
+	return fatalError{fmt.Errorf(format, args...)}
+}
+
+// IsFatal reports whether err, returned by Validate, must keep the server
+// from starting. other problems are only warnings
+func IsFatal(err error) bool {
+	var f fatalError
+	return errors.As(err, &f)
+}
+
+// Validate reports every structural problem of cfg, each error tells where
+// the problem is, like contest, game index, key index and cell
+func Validate(cfg Config) (problems []error) {
+	if !cfg.StartTime.IsZero() && !cfg.EndTime.IsZero() && !cfg.EndTime.After(cfg.StartTime) {
+		problems = append(problems, errors.New("end_time must be after start_time"))
+	}
+	if cfg.Attempts.Cooldown < 0 || cfg.Attempts.Penalty < 0 {
+		problems = append(problems, errors.New("attempts: cooldown and penalty must not be negative"))
+	}
+	if cfg.Hints.Limit < 0 || cfg.Hints.Penalty < 0 {
+		problems = append(problems, errors.New("hints: limit and penalty must not be negative"))
+	}
+	names := map[string]bool{"": true}
+	for i, c := range cfg.Contests {
+		if names[c.Name] {
+			problems = append(problems, fmt.Errorf("contests[%d]: name %q is empty or duplicate", i, c.Name))
+		}
+		names[c.Name] = true
+	}
+	// users are looked up by username alone, so it must be unique among
+	// all contests
+	usernames := make(map[string]bool)
+	for _, c := range cfg.AllContests() {
+		prefix := ""
+		if c.Name != "" {
+			prefix = fmt.Sprintf("contest %s: ", c.Name)
+		}
+		for _, u := range c.Users {
+			if usernames[u.Username] {
+				problems = append(problems, fmt.Errorf("%susername %s is used more than once", prefix, u.Username))
+			}
+			usernames[u.Username] = true
+			if !u.Admin && u.Group.Name == "" {
+				problems = append(problems, fmt.Errorf("%suser %s has no group", prefix, u.Username))
+			}
+		}
+		if derived, disagrees := c.PassphraseDisagrees(); disagrees {
+			problems = append(problems, fmt.Errorf("%spassphrase %q doesn't match %q of passphrase cells",
+				prefix, c.Passphrase, derived))
+		}
+		for _, err := range validateContest(c) {
+			problems = append(problems, fmt.Errorf("%s%w", prefix, err))
+		}
+	}
+	return
+}
+
+func validateContest(c Contest) (problems []error) {
+	if len(c.Games) == 0 {
+		for _, u := range c.Users {
+			if !u.Admin {
+				return []error{fatalf("contest has no games")}
+			}
+		}
+		// only admins, like the default contest of a config that has all
+		// players in contests
+		return nil
+	}
+	passphraseCells := 0
+	for i, g := range c.Games {
+		location := fmt.Sprintf("game %d", i)
+		if g.File != "" {
+			location += fmt.Sprintf(" (%s)", g.File)
+		}
+		for _, err := range g.validate() {
+			problems = append(problems, fmt.Errorf("%s: %w", location, err))
+		}
+		for _, k := range g.Actual.Keys {
+			if k.Key.State == key.PASSPHRASE {
+				passphraseCells++
+			}
+		}
+	}
+	switch {
+	case passphraseCells == 0 && c.PassphraseRule != PassphraseManual:
+		// the derived passphrase would be empty
+		problems = append(problems, fatalf("no game has passphrase cells for passphrase rule %s", c.PassphraseRule))
+	case passphraseCells == 0:
+		problems = append(problems, errors.New("no game has passphrase cells, passphrase can't be found"))
+	}
+	return
+}
+
+func (g Game) inGrid(row, col int) bool {
+	return row >= 0 && row < g.Rows && col >= 0 && col < g.Cols
+}
+
+// validate reports problems of grid, initial cell and clues of g
+func (g Game) validate() (problems []error) {
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+	fatal := func(format string, args ...interface{}) {
+		problems = append(problems, fatalf(format, args...))
+	}
+	if g.Rows <= 0 || g.Cols <= 0 {
+		fatal("grid must have positive rows and cols, got %dx%d", g.Rows, g.Cols)
+		return
+	}
+	// cells maps every cell to index of its key
+	cells := make(map[Cell]int)
+	for i, k := range g.Actual.Keys {
+		cell := Cell{k.Row, k.Col}
+		if !g.inGrid(k.Row, k.Col) {
+			fatal("keys[%d]: cell %d, %d is out of %dx%d grid", i, k.Row, k.Col, g.Rows, g.Cols)
+			continue
+		}
+		if j, ok := cells[cell]; ok {
+			fatal("keys[%d]: cell %d, %d is already set by keys[%d]", i, k.Row, k.Col, j)
+			continue
+		}
+		cells[cell] = i
+		if k.Key.State != key.READONLY && (k.Key.MustBe == 0 || k.Key.MustBe == key.EMPTY) {
+			fatal("keys[%d]: cell %d, %d has no mustbe", i, k.Row, k.Col)
+		}
+	}
+	open := func(row, col int) bool {
+		i, ok := cells[Cell{row, col}]
+		return ok && g.Actual.Keys[i].Key.State != key.READONLY
+	}
+	for row := 0; row < g.Rows; row++ {
+		for col := 0; col < g.Cols; col++ {
+			if _, ok := cells[Cell{row, col}]; !ok {
+				fatal("cell %d, %d has no key", row, col)
+			}
+		}
+	}
+
+	switch {
+	case !g.inGrid(g.InitialRow, g.InitialCol):
+		fatal("initial cell %d, %d is out of grid", g.InitialRow, g.InitialCol)
+	case !open(g.InitialRow, g.InitialCol):
+		fatal("initial cell %d, %d is readonly", g.InitialRow, g.InitialCol)
+	default:
+		// cursor moves only between neighbouring editable cells
+		reached := map[Cell]bool{{g.InitialRow, g.InitialCol}: true}
+		queue := []Cell{{g.InitialRow, g.InitialCol}}
+		for len(queue) != 0 {
+			c := queue[0]
+			queue = queue[1:]
+			for _, n := range []Cell{{c.Row - 1, c.Col}, {c.Row + 1, c.Col}, {c.Row, c.Col - 1}, {c.Row, c.Col + 1}} {
+				if !reached[n] && open(n.Row, n.Col) {
+					reached[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+		for row := 0; row < g.Rows; row++ {
+			for col := 0; col < g.Cols; col++ {
+				if open(row, col) && !reached[Cell{row, col}] {
+					report("cell %d, %d is unreachable from initial cell", row, col)
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range g.Clues {
+		id := fmt.Sprintf("clues[%d] (%d %s)", i, c.Number, c.Direction)
+		if seen[fmt.Sprintf("%d %s", c.Number, c.Direction)] {
+			report("%s: duplicate clue", id)
+			continue
+		}
+		seen[fmt.Sprintf("%d %s", c.Number, c.Direction)] = true
+		if c.Length <= 0 {
+			report("%s: length must be positive, got %d", id, c.Length)
+			continue
+		}
+		valid := true
+		for j := 0; j < c.Length; j++ {
+			row, col := c.Cell(j)
+			if !g.inGrid(row, col) {
+				fatal("%s: cell %d, %d is out of grid", id, row, col)
+				valid = false
+				break
+			}
+			if !open(row, col) {
+				report("%s: cell %d, %d is readonly or has no key", id, row, col)
+				valid = false
+			}
+		}
+		if !valid {
+			continue
+		}
+		// a clue must cover a whole word of the grid
+		if row, col := c.Cell(-1); g.inGrid(row, col) && open(row, col) {
+			report("%s: word continues before the clue, at cell %d, %d", id, row, col)
+		}
+		if row, col := c.Cell(c.Length); g.inGrid(row, col) && open(row, col) {
+			report("%s: word continues after the clue, at cell %d, %d", id, row, col)
+		}
+	}
+	return
+}
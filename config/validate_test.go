@@ -0,0 +1,152 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+// validConfig returns a config with catGrid as its only game
+func validConfig(t *testing.T) Config {
+	t.Helper()
+	g, err := ReadGrid(strings.NewReader(catGrid), "cat.grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{
+		Games: []Game{g},
+		Users: []user.User{{Username: "alice", Group: user.Group{Name: "red"}}},
+	}
+}
+
+// gridGame returns a game with the given rows of a grid file and no clue
+// texts
+func gridGame(rows ...string) Game {
+	var grid [][]rune
+	for _, r := range rows {
+		grid = append(grid, []rune(r))
+	}
+	return buildGrid(grid)
+}
+
+func TestValidate(t *testing.T) {
+	type problem struct {
+		err   string
+		fatal bool
+	}
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		want   []problem
+	}{
+		{name: "valid", modify: func(cfg *Config) {}},
+		{name: "end before start", modify: func(cfg *Config) {
+			cfg.StartTime = time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+			cfg.EndTime = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		}, want: []problem{{err: "end_time must be after start_time"}}},
+		{name: "negative attempts and hints", modify: func(cfg *Config) {
+			cfg.Attempts.Cooldown = -1
+			cfg.Hints.Penalty = -1
+		}, want: []problem{
+			{err: "attempts: cooldown and penalty must not be negative"},
+			{err: "hints: limit and penalty must not be negative"},
+		}},
+		{name: "unnamed contest", modify: func(cfg *Config) {
+			cfg.Contests = []Contest{{}}
+		}, want: []problem{{err: `contests[0]: name "" is empty or duplicate`}}},
+		{name: "username in two contests", modify: func(cfg *Config) {
+			cfg.Contests = []Contest{{Name: "finals", Games: cfg.Games, Users: cfg.Users}}
+		}, want: []problem{{err: "contest finals: username alice is used more than once"}}},
+		{name: "player without group", modify: func(cfg *Config) {
+			cfg.Users = append(cfg.Users, user.User{Username: "bob"}, user.User{Username: "root", Admin: true})
+		}, want: []problem{{err: "user bob has no group"}}},
+		{name: "passphrase doesn't match cells", modify: func(cfg *Config) {
+			cfg.Passphrase = "x"
+		}, want: []problem{{err: `passphrase "x" doesn't match "E" of passphrase cells`}}},
+		{name: "players without games", modify: func(cfg *Config) {
+			cfg.Games = nil
+		}, want: []problem{{err: "contest has no games", fatal: true}}},
+		{name: "only admins without games", modify: func(cfg *Config) {
+			cfg.Games = nil
+			cfg.Users = []user.User{{Username: "root", Admin: true}}
+		}},
+		{name: "no passphrase cells", modify: func(cfg *Config) {
+			cfg.Games = []Game{gridGame("AB")}
+		}, want: []problem{{err: "no game has passphrase cells, passphrase can't be found"}}},
+		{name: "no passphrase cells with a rule", modify: func(cfg *Config) {
+			cfg.Games = []Game{gridGame("AB")}
+			cfg.Passphrase, cfg.PassphraseRule = "", PassphraseRows
+		}, want: []problem{{err: "no game has passphrase cells for passphrase rule rows", fatal: true}}},
+		{name: "empty grid", modify: func(cfg *Config) {
+			cfg.Games[0].Rows = 0
+		}, want: []problem{{err: "game 0: grid must have positive rows and cols, got 0x3", fatal: true}}},
+		{name: "key out of grid", modify: func(cfg *Config) {
+			cfg.Games[0].addKey(3, 0, key.Key{Char: key.EMPTY, MustBe: key.Letters['A'], State: key.EDITABLE})
+		}, want: []problem{{err: "game 0: keys[9]: cell 3, 0 is out of 3x3 grid", fatal: true}}},
+		{name: "duplicate key", modify: func(cfg *Config) {
+			cfg.Games[0].File = "cat.grid"
+			cfg.Games[0].addKey(0, 0, key.Key{Char: key.EMPTY, MustBe: key.Letters['A'], State: key.EDITABLE})
+		}, want: []problem{{err: "game 0 (cat.grid): keys[9]: cell 0, 0 is already set by keys[0]", fatal: true}}},
+		{name: "key without mustbe", modify: func(cfg *Config) {
+			cfg.Games[0].Actual.Keys[0].Key.MustBe = key.EMPTY
+		}, want: []problem{{err: "game 0: keys[0]: cell 0, 0 has no mustbe", fatal: true}}},
+		{name: "cell without key", modify: func(cfg *Config) {
+			cfg.Games[0].Actual.Keys = cfg.Games[0].Actual.Keys[:8]
+			cfg.Games[0].Clues = cfg.Games[0].Clues[:1]
+		}, want: []problem{
+			{err: "game 0: cell 2, 2 has no key", fatal: true},
+			{err: "no game has passphrase cells, passphrase can't be found"},
+		}},
+		{name: "initial cell out of grid", modify: func(cfg *Config) {
+			cfg.Games[0].InitialRow = 3
+		}, want: []problem{{err: "game 0: initial cell 3, 0 is out of grid", fatal: true}}},
+		{name: "readonly initial cell", modify: func(cfg *Config) {
+			cfg.Games[0].InitialRow, cfg.Games[0].InitialCol = 1, 1
+		}, want: []problem{{err: "game 0: initial cell 1, 1 is readonly", fatal: true}}},
+		{name: "unreachable cell", modify: func(cfg *Config) {
+			cfg.Games = []Game{gridGame("AB#", "##c")}
+		}, want: []problem{{err: "game 0: cell 1, 2 is unreachable from initial cell"}}},
+		{name: "duplicate clue", modify: func(cfg *Config) {
+			g := &cfg.Games[0]
+			g.Clues = append(g.Clues, g.Clues[0])
+		}, want: []problem{{err: "game 0: clues[4] (1 across): duplicate clue"}}},
+		{name: "empty clue", modify: func(cfg *Config) {
+			cfg.Games[0].Clues[0].Length = 0
+		}, want: []problem{{err: "game 0: clues[0] (1 across): length must be positive, got 0"}}},
+		{name: "clue out of grid", modify: func(cfg *Config) {
+			cfg.Games[0].Clues[0].Length = 4
+		}, want: []problem{{err: "game 0: clues[0] (1 across): cell 0, 3 is out of grid", fatal: true}}},
+		{name: "clue over a block", modify: func(cfg *Config) {
+			cfg.Games[0].Clues[0].Row = 1
+		}, want: []problem{{err: "game 0: clues[0] (1 across): cell 1, 1 is readonly or has no key"}}},
+		{name: "clue of part of a word", modify: func(cfg *Config) {
+			cfg.Games[0].Clues[0].Length = 2
+		}, want: []problem{{err: "game 0: clues[0] (1 across): word continues after the clue, at cell 0, 2"}}},
+		{name: "fatal problem of a named contest", modify: func(cfg *Config) {
+			g := cfg.Games[0]
+			g.Rows = -1
+			cfg.Contests = []Contest{{Name: "finals", Games: []Game{g}}}
+		}, want: []problem{{err: "contest finals: game 0: grid must have positive rows and cols, got -1x3", fatal: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.modify(&cfg)
+			problems := Validate(cfg)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d problems", problems, len(tt.want))
+			}
+			for i, err := range problems {
+				if err.Error() != tt.want[i].err {
+					t.Errorf("problem %d = %q, want %q", i, err, tt.want[i].err)
+				}
+				if IsFatal(err) != tt.want[i].fatal {
+					t.Errorf("IsFatal(%q) = %v, want %v", err, IsFatal(err), tt.want[i].fatal)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/amirkhaki/crossword/user"
+)
+
+// GroupStatus summarizes state of a group for admins
+type GroupStatus struct {
+	Group            user.Group
+	CurrentGameIndex int
+	GameCount        int
+	// Correct and Total are number of correct keys and keys to be filled in
+	// current game
+	Correct int
+	Total   int
+	Started bool
+	Ended   bool
+	Elapsed time.Duration
+}
+
+// GetGroupStatuses returns status of every group sorted by contest and name
+func (d *Data) GetGroupStatuses() (l []GroupStatus) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	for grp, g := range d.games {
+		st := GroupStatus{
+			Group:            grp,
+			CurrentGameIndex: g.currentGameIndex,
+			GameCount:        len(g.states),
+			Started:          g.started,
+			Ended:            g.endTime != 0,
+		}
+		if len(g.states) != 0 {
+			st.Correct, st.Total = g.states[g.currentGameIndex].progress()
+		}
+		if g.started {
+			end := now
+			if g.endTime != 0 {
+				end = g.endTime
+			}
+			st.Elapsed = time.Duration(end-g.startTime) * time.Millisecond
+		}
+		l = append(l, st)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Group.Contest != l[j].Group.Contest {
+			return l[i].Group.Contest < l[j].Group.Contest
+		}
+		return l[i].Group.Name < l[j].Group.Name
+	})
+	return
+}
+
+// GroupReset brings group back to the state it had when it was added
+func (d *Data) GroupReset(grp user.Group) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		return GroupNotFoundError(fmt.Errorf("GroupReset: Group not found"))
+	}
+	reset := groupState{passphrase: g.passphrase, cfgs: g.cfgs}
+	for _, cfg := range g.cfgs {
+		reset.states = append(reset.states, newGameState(cfg))
+	}
+	d.games[grp] = reset
+	delete(d.cursors, grp)
+	d.notify(grp)
+	return nil
+}
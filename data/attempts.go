@@ -0,0 +1,75 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amirkhaki/crossword/user"
+)
+
+type PassphraseCooldownError error
+
+// Attempts are answers a group gave to the passphrase
+type Attempts struct {
+	Count int
+	Wrong int
+	// RetryAt is when group may answer again after a wrong answer, zero if
+	// there is no cooldown
+	RetryAt time.Time
+}
+
+// SetPassphrasePenalties sets how long a group waits after a wrong
+// passphrase and how much time is added to its elapsed time for it
+func (d *Data) SetPassphrasePenalties(cooldown, penalty time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cooldown = cooldown
+	d.penalty = penalty
+}
+
+func (d *Data) GetGroupAttempts(grp user.Group) (a Attempts, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GetGroupAttempts: Group not found"))
+		return
+	}
+
+	a.Count = g.attempts
+	a.Wrong = g.wrongAttempts
+	if g.retryAt != 0 {
+		a.RetryAt = time.UnixMilli(g.retryAt)
+	}
+	return
+}
+
+// GroupIsPassphraseCorrect checks passphrase and records the attempt, a
+// group waiting for its cooldown gets PassphraseCooldownError and the
+// attempt is not counted
+func (d *Data) GroupIsPassphraseCorrect(grp user.Group, passphrase string) (_ bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GroupIsPassphraseCorrect: Group not found"))
+		return
+	}
+	now := time.Now().UnixMilli()
+	if now < g.retryAt {
+		err = PassphraseCooldownError(fmt.Errorf("GroupIsPassphraseCorrect: wait %d seconds before trying again",
+			(g.retryAt-now+999)/1000))
+		return
+	}
+	g.attempts++
+	correct := g.passphrase.Check(passphrase)
+	if !correct {
+		g.wrongAttempts++
+		if d.cooldown > 0 {
+			g.retryAt = now + d.cooldown.Milliseconds()
+		}
+	}
+	d.games[grp] = g
+	d.notify(grp)
+	return correct, nil
+}
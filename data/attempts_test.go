@@ -0,0 +1,96 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/user"
+)
+
+// penaltyOf returns penalty of grp on the leaderboard of its contest
+func penaltyOf(t *testing.T, d *Data, grp user.Group) time.Duration {
+	t.Helper()
+	for _, item := range d.GetLeaderboard(grp.Contest) {
+		if item.groupName == grp.Name {
+			if item.elapsed < item.penalty {
+				t.Errorf("elapsed %d doesn't include penalty %d", item.elapsed, item.penalty)
+			}
+			return time.Duration(item.penalty) * time.Millisecond
+		}
+	}
+	t.Fatalf("group %s is not on the leaderboard", grp.Name)
+	return 0
+}
+
+func TestGroupIsPassphraseCorrect(t *testing.T) {
+	red := user.Group{Name: "red"}
+	type answer struct {
+		text     string
+		correct  bool
+		cooldown bool
+	}
+	tests := []struct {
+		name     string
+		cooldown time.Duration
+		penalty  time.Duration
+		answers  []answer
+		want     Attempts
+	}{
+		{name: "correct", answers: []answer{{text: "To", correct: true}}, want: Attempts{Count: 1}},
+		{name: "wrong without cooldown", penalty: time.Minute, answers: []answer{
+			{text: "no"},
+			{text: "ot"},
+			{text: "to", correct: true},
+		}, want: Attempts{Count: 3, Wrong: 2}},
+		{name: "wrong with cooldown", cooldown: time.Hour, penalty: time.Minute, answers: []answer{
+			{text: "no"},
+			{text: "to", cooldown: true},
+			{text: "no", cooldown: true},
+		}, want: Attempts{Count: 1, Wrong: 1}},
+		{name: "correct is not delayed", cooldown: time.Hour, answers: []answer{
+			{text: "to", correct: true},
+			{text: "to", correct: true},
+		}, want: Attempts{Count: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewData()
+			d.SetPassphrasePenalties(tt.cooldown, tt.penalty)
+			if err := d.AddGroup(red, []config.Game{testGame("CAt")}, config.Passphrase{Text: "to"}); err != nil {
+				t.Fatal(err)
+			}
+			for i, a := range tt.answers {
+				correct, err := d.GroupIsPassphraseCorrect(red, a.text)
+				if a.cooldown {
+					if err == nil || !strings.Contains(err.Error(), "wait 3600 seconds") {
+						t.Errorf("answer %d: error = %v, want cooldown", i, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("answer %d: %v", i, err)
+				}
+				if correct != a.correct {
+					t.Errorf("answer %d: correct = %v, want %v", i, correct, a.correct)
+				}
+			}
+
+			a, err := d.GetGroupAttempts(red)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Count != tt.want.Count || a.Wrong != tt.want.Wrong {
+				t.Errorf("attempts = %d, %d wrong, want %d, %d wrong", a.Count, a.Wrong, tt.want.Count, tt.want.Wrong)
+			}
+			waiting := tt.cooldown > 0 && a.Wrong > 0
+			if a.RetryAt.IsZero() == waiting || time.Until(a.RetryAt) > tt.cooldown {
+				t.Errorf("retry at %v, cooldown %v", a.RetryAt, tt.cooldown)
+			}
+			if got, want := penaltyOf(t, d, red), time.Duration(a.Wrong)*tt.penalty; got != want {
+				t.Errorf("penalty = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/amirkhaki/crossword/user"
+)
+
+// Cursor is position of a connected session in current game of its group,
+// a user may have several sessions
+type Cursor struct {
+	Session  int
+	Username string
+	Row      int
+	Col      int
+}
+
+func (d *Data) GroupSetCursor(grp user.Group, session int, username string, row, col int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		return GroupNotFoundError(fmt.Errorf("GroupSetCursor: Group not found"))
+	}
+
+	if !g.states[g.currentGameIndex].isValidKey(row, col) {
+		return fmt.Errorf("GroupSetCursor: invalid row col: %d, %d", row, col)
+	}
+
+	if _, ok := d.cursors[grp]; !ok {
+		d.cursors[grp] = make(map[int]Cursor)
+	}
+	d.cursors[grp][session] = Cursor{Session: session, Username: username, Row: row, Col: col}
+	d.notify(grp)
+	return nil
+}
+
+func (d *Data) GroupRemoveCursor(grp user.Group, session int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.games[grp]; !ok {
+		return GroupNotFoundError(fmt.Errorf("GroupRemoveCursor: Group not found"))
+	}
+
+	delete(d.cursors[grp], session)
+	d.notify(grp)
+	return nil
+}
+
+// GetGroupCursors returns cursors of every connected session of grp, sorted
+// by username
+func (d *Data) GetGroupCursors(grp user.Group) (l []Cursor, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.games[grp]; !ok {
+		err = GroupNotFoundError(fmt.Errorf("GetGroupCursors: Group not found"))
+		return
+	}
+
+	for _, c := range d.cursors[grp] {
+		l = append(l, c)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Username != l[j].Username {
+			return l[i].Username < l[j].Username
+		}
+		return l[i].Session < l[j].Session
+	})
+	return
+}
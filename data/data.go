@@ -2,7 +2,6 @@ package data
 
 import (
 	"fmt"
-	"log"
 	"sort"
 	"strings"
 	"sync"
@@ -20,6 +19,29 @@ type gameState struct {
 	initialRow int
 	initialCol int
 	questions  []string
+	clues      []config.Clue
+	// startTime and endTime of this game in milliseconds, zero until the
+	// game is started or solved
+	startTime int64
+	endTime   int64
+}
+
+func newGameState(cfg config.Game) gameState {
+	state := gameState{}
+	state.questions = cfg.Questions
+	state.clues = cfg.Clues
+	state.rows = cfg.Rows
+	state.cols = cfg.Cols
+	state.initialCol = cfg.InitialCol
+	state.initialRow = cfg.InitialRow
+	state.actual = make([][]key.Key, cfg.Rows)
+	for i := 0; i < cfg.Rows; i++ {
+		state.actual[i] = make([]key.Key, cfg.Cols)
+	}
+	for _, k := range cfg.Actual.Keys {
+		state.actual[k.Row][k.Col] = k.Key
+	}
+	return state
 }
 
 func (g gameState) ended() bool {
@@ -33,7 +55,28 @@ func (g gameState) ended() bool {
 	return true
 }
 
+// progress returns number of correct keys and number of keys to be filled
+func (g gameState) progress() (correct, total int) {
+	for i := 0; i < g.rows; i++ {
+		for j := 0; j < g.cols; j++ {
+			k := g.actual[i][j]
+			if k.State == key.READONLY {
+				continue
+			}
+			total++
+			if k.Char == k.MustBe {
+				correct++
+			}
+		}
+	}
+	return
+}
+
 func (g gameState) isValidKey(row, col int) bool {
+	if row < 0 || col < 0 {
+		return false
+	}
+
 	if row >= g.rows {
 		return false
 	}
@@ -49,6 +92,44 @@ type GroupItem struct {
 	startTime int64
 	endTime   int64
 	groupName string
+	// completed is number of solved games, correct and total are number of
+	// correct keys and keys to be filled in current game
+	completed int
+	correct   int
+	total     int
+	// elapsed is time spent so far in milliseconds, penalty included
+	elapsed int64
+	// penalty is time added for wrong passphrases and hints in milliseconds
+	penalty       int64
+	attempts      int
+	wrongAttempts int
+	hints         int
+	splits        []Split
+}
+
+// Split is start and end of a single game, End is zero if game is not solved
+type Split struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns time spent on the game, or zero if it is not solved
+func (s Split) Duration() time.Duration {
+	if s.End.IsZero() || s.Start.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+func (g gameState) split() Split {
+	s := Split{}
+	if g.startTime != 0 {
+		s.Start = time.UnixMilli(g.startTime)
+	}
+	if g.endTime != 0 {
+		s.End = time.UnixMilli(g.endTime)
+	}
+	return s
 }
 
 func (g GroupItem) FilterValue() string {
@@ -60,21 +141,97 @@ func (g GroupItem) Title() string {
 }
 
 func (g GroupItem) Desciption() string {
-	log.Println(g.endTime, " ", g.startTime)
-	return fmt.Sprintf("Ended in %d seconds", (g.endTime-g.startTime)/1000)
+	var desc string
+	if g.endTime == 0 {
+		desc = fmt.Sprintf("Solved %d games, %d/%d keys of current game in %d seconds",
+			g.completed, g.correct, g.total, g.elapsed/1000)
+	} else {
+		desc = fmt.Sprintf("Ended in %d seconds", g.elapsed/1000)
+	}
+	if g.penalty != 0 {
+		desc += fmt.Sprintf(" (%d seconds penalty)", g.penalty/1000)
+	}
+	if g.attempts != 0 {
+		desc += fmt.Sprintf("\nPassphrase attempts: %d, %d wrong", g.attempts, g.wrongAttempts)
+	}
+	if g.hints != 0 {
+		desc += fmt.Sprintf("\nHints used: %d", g.hints)
+	}
+	var splits []string
+	for i, s := range g.splits {
+		if s.End.IsZero() {
+			continue
+		}
+		splits = append(splits, fmt.Sprintf("#%d %ds", i+1, int64(s.Duration().Seconds())))
+	}
+	if len(splits) != 0 {
+		desc += "\nSplits: " + strings.Join(splits, " • ")
+	}
+	return desc
 }
 
-type Data struct {
-	mu    sync.Mutex
-	games map[user.Group]struct {
-		states           []gameState
-		currentGameIndex int
-		isAfterGame      bool
-		startTime        int64
-		endTime          int64
-		started          bool
-		passphrase       string
+func (g GroupItem) Splits() []Split {
+	return g.splits
+}
+
+// less ranks groups that ended first by duration, then the rest by
+// progress and elapsed time
+func (g GroupItem) less(o GroupItem) bool {
+	if (g.endTime != 0) != (o.endTime != 0) {
+		return g.endTime != 0
+	}
+	if g.endTime != 0 {
+		return g.elapsed < o.elapsed
+	}
+	if g.completed != o.completed {
+		return g.completed > o.completed
 	}
+	if g.correct != o.correct {
+		return g.correct > o.correct
+	}
+	return g.elapsed < o.elapsed
+}
+
+type groupState struct {
+	states           []gameState
+	currentGameIndex int
+	isAfterGame      bool
+	startTime        int64
+	endTime          int64
+	started          bool
+	passphrase       config.Passphrase
+	// attempts and wrongAttempts count answers to passphrase, retryAt is
+	// end of cooldown after a wrong answer in milliseconds
+	attempts      int
+	wrongAttempts int
+	retryAt       int64
+	// hints is number of hints used
+	hints int
+	// collected is passphrase letters of solved games, in the order games
+	// were solved
+	collected []PassphraseLetter
+	// cfgs are kept to build states again when group is reset
+	cfgs []config.Game
+}
+
+type Data struct {
+	mu      sync.Mutex
+	hub     *hub
+	cursors map[user.Group]map[int]Cursor
+	games   map[user.Group]groupState
+	// start and end of the contest, zero if not scheduled
+	start time.Time
+	end   time.Time
+	// cooldown is wait after a wrong passphrase, penalty is added to time
+	// of the group for each wrong passphrase
+	cooldown time.Duration
+	penalty  time.Duration
+	// hintLimit is number of hints each group may use, hintPenalty is
+	// added to time of the group for each of them
+	hintLimit   int
+	hintPenalty time.Duration
+	// version is increased on every change, to know when to save
+	version uint64
 }
 
 func (d *Data) GroupAllGameEnded(grp user.Group) (ok bool, err error) {
@@ -84,7 +241,7 @@ func (d *Data) GroupAllGameEnded(grp user.Group) (ok bool, err error) {
 	if !ok {
 		err = GroupNotFoundError(fmt.Errorf("GetGroupInitialCol: Group not found"))
 	}
-	ok = (g.endTime != 0)
+	ok = (g.endTime != 0) || d.contestEnded()
 	return
 
 }
@@ -96,43 +253,88 @@ func (d *Data) GroupEndAllGame(grp user.Group) error {
 	if !ok {
 		return GroupNotFoundError(fmt.Errorf("GetGroupInitialCol: Group not found"))
 	}
+	if d.contestEnded() {
+		return ContestEndedError(fmt.Errorf("GroupEndAllGame: contest has ended"))
+	}
+	if g.endTime != 0 {
+		return GroupEndedError(fmt.Errorf("GroupEndAllGame: group has already ended"))
+	}
 	g.endTime = time.Now().UnixMilli()
+	if !g.started {
+		// ended by an admin before typing anything
+		g.started = true
+		g.startTime = g.endTime
+		if !d.start.IsZero() {
+			g.startTime = d.start.UnixMilli()
+		}
+	}
 	d.games[grp] = g
+	d.notify(grp)
 	return nil
 }
 
-func (d *Data) GetItems() (l []GroupItem) {
+func (d *Data) GetItems(contest string) (l []GroupItem) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	for k, v := range d.games {
-		if v.endTime == 0 {
-			continue
-		}
-		l = append(l, GroupItem{startTime: v.startTime, endTime: v.endTime, groupName: k.Name})
-	}
-	sort.Slice(l, func(i, j int) bool {
-		return (l[i].endTime - l[i].startTime) <= (l[j].endTime - l[j].startTime)
-	})
-	return
+	// after deadline every group is ranked, finished or not
+	return d.items(contest, d.contestEnded())
 }
 
-func (d *Data) GroupIsPassphraseCorrect(grp user.Group, passphrase string) (_ bool, err error) {
+// GetLeaderboard ranks every group of contest, including the ones still
+// playing
+func (d *Data) GetLeaderboard(contest string) []GroupItem {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	g, ok := d.games[grp]
-	if !ok {
-		err = GroupNotFoundError(fmt.Errorf("GetGroupInitialCol: Group not found"))
-		return
-	}
-	passphrase = strings.ToLower(passphrase)
+	return d.items(contest, true)
+}
 
-	if strings.ToLower(g.passphrase) == passphrase {
-		return true, nil
+// items returns ranked groups of contest, unfinished groups are included
+// only if all is true
+func (d *Data) items(contest string, all bool) (l []GroupItem) {
+	now := d.now()
+	for k, v := range d.games {
+		if k.Contest != contest {
+			continue
+		}
+		if v.endTime == 0 && !all {
+			continue
+		}
+		item := GroupItem{startTime: v.startTime, endTime: v.endTime, groupName: k.Name}
+		item.completed = v.currentGameIndex
+		if v.isAfterGame {
+			item.completed++
+		}
+		if len(v.states) != 0 {
+			item.correct, item.total = v.states[v.currentGameIndex].progress()
+		}
+		for _, st := range v.states {
+			item.splits = append(item.splits, st.split())
+		}
+		if v.endTime != 0 {
+			item.elapsed = v.endTime - v.startTime
+		} else if v.started {
+			item.elapsed = now - v.startTime
+		}
+		item.attempts, item.wrongAttempts = v.attempts, v.wrongAttempts
+		item.hints = v.hints
+		item.penalty = int64(v.wrongAttempts)*d.penalty.Milliseconds() +
+			int64(v.hints)*d.hintPenalty.Milliseconds()
+		item.elapsed += item.penalty
+		l = append(l, item)
 	}
-	return false, nil
+	sort.SliceStable(l, func(i, j int) bool {
+		return l[i].less(l[j])
+	})
+	return
 }
 
 type GroupNotFoundError error
+type ContestNotStartedError error
+type ContestEndedError error
+
+// GroupEndedError is returned for changes to a group whose games have all
+// ended
+type GroupEndedError error
 
 func (d *Data) GetGroupInitialCol(grp user.Group) (_ int, err error) {
 	d.mu.Lock()
@@ -211,6 +413,18 @@ func (d *Data) GetGroupQuestions(grp user.Group) (_ []string, err error) {
 	return g.states[g.currentGameIndex].questions, nil
 }
 
+func (d *Data) GetGroupClues(grp user.Group) (_ []config.Clue, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GetGroupClues: Group not found"))
+		return
+	}
+
+	return g.states[g.currentGameIndex].clues, nil
+}
+
 func (d *Data) GroupInsertKeyAt(grp user.Group, k key.Key, row, col int) (err error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -225,18 +439,77 @@ func (d *Data) GroupInsertKeyAt(grp user.Group, k key.Key, row, col int) (err er
 		return
 	}
 
+	if !d.contestStarted() {
+		err = ContestNotStartedError(fmt.Errorf("GroupInsertKeyAt: contest has not started"))
+		return
+	}
+
+	if d.contestEnded() {
+		err = ContestEndedError(fmt.Errorf("GroupInsertKeyAt: contest has ended"))
+		return
+	}
+
+	if g.endTime != 0 {
+		err = GroupEndedError(fmt.Errorf("GroupInsertKeyAt: group has ended"))
+		return
+	}
+
+	d.setKey(&g, k, row, col)
+	d.games[grp] = g
+	d.notify(grp)
+	return nil
+}
+
+// setKey puts k at row, col of current game of g, starting the group's clock
+// on its first key and ending the game once it is solved
+func (d *Data) setKey(g *groupState, k key.Key, row, col int) {
 	g.states[g.currentGameIndex].actual[row][col] = k
 
 	if !g.started {
 		g.started = true
 		g.startTime = time.Now().UnixMilli()
+		if !d.start.IsZero() {
+			// every group is timed from the scheduled start
+			g.startTime = d.start.UnixMilli()
+		}
+		g.states[g.currentGameIndex].startTime = g.startTime
 	}
 
 	if g.states[g.currentGameIndex].ended() {
 		g.isAfterGame = true
+		if g.states[g.currentGameIndex].endTime == 0 {
+			g.states[g.currentGameIndex].endTime = time.Now().UnixMilli()
+			g.collect(g.currentGameIndex)
+		}
 	}
-	d.games[grp] = g
-	return nil
+}
+
+func (d *Data) GetGroupCurrentGameIndex(grp user.Group) (_ int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GetGroupCurrentGameIndex: Group not found"))
+		return
+	}
+
+	return g.currentGameIndex, nil
+}
+
+// GetGroupSplits returns start and end of every game of grp
+func (d *Data) GetGroupSplits(grp user.Group) (l []Split, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GetGroupSplits: Group not found"))
+		return
+	}
+
+	for _, st := range g.states {
+		l = append(l, st.split())
+	}
+	return
 }
 
 func (d *Data) GroupIsAfterGame(grp user.Group) (_ bool, err error) {
@@ -265,7 +538,7 @@ func (d *Data) GroupGameEnded(grp user.Group) (_ bool, err error) {
 
 type GroupExistsError error
 
-func (d *Data) AddGroup(grp user.Group, cfgs []config.Game, ps string) error {
+func (d *Data) AddGroup(grp user.Group, cfgs []config.Game, ps config.Passphrase) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	g, ok := d.games[grp]
@@ -273,22 +546,10 @@ func (d *Data) AddGroup(grp user.Group, cfgs []config.Game, ps string) error {
 		return GroupExistsError(fmt.Errorf("AddGroup: group already exists"))
 	}
 	for _, cfg := range cfgs {
-		state := gameState{}
-		state.questions = cfg.Questions
-		state.rows = cfg.Rows
-		state.cols = cfg.Cols
-		state.initialCol = cfg.InitialCol
-		state.initialRow = cfg.InitialRow
-		state.actual = make([][]key.Key, cfg.Rows)
-		for i := 0; i < cfg.Rows; i++ {
-			state.actual[i] = make([]key.Key, cfg.Cols)
-		}
-		for _, k := range cfg.Actual.Keys {
-			state.actual[k.Row][k.Col] = k.Key
-		}
-		g.states = append(g.states, state)
+		g.states = append(g.states, newGameState(cfg))
 		g.passphrase = ps
 	}
+	g.cfgs = cfgs
 	d.games[grp] = g
 	return nil
 }
@@ -302,25 +563,25 @@ func (d *Data) GroupGotoNextGame(grp user.Group) error {
 	if !ok {
 		return GroupNotFoundError(fmt.Errorf("GroupGotoNextGame: Group not found"))
 	}
+	if g.endTime != 0 {
+		return GroupEndedError(fmt.Errorf("GroupGotoNextGame: group has ended"))
+	}
 	if len(g.states)-1 == g.currentGameIndex {
 		return AllGamesDoneError(fmt.Errorf("GroupGotoNextGame: all games done"))
 	}
 	g.currentGameIndex++
 	g.isAfterGame = false
+	g.states[g.currentGameIndex].startTime = time.Now().UnixMilli()
 	d.games[grp] = g
+	delete(d.cursors, grp)
+	d.notify(grp)
 	return nil
 }
 
 func NewData() *Data {
 	d := Data{}
-	d.games = make(map[user.Group]struct {
-		states           []gameState
-		currentGameIndex int
-		isAfterGame      bool
-		startTime        int64
-		endTime          int64
-		started          bool
-		passphrase       string
-	})
+	d.hub = newHub()
+	d.cursors = make(map[user.Group]map[int]Cursor)
+	d.games = make(map[user.Group]groupState)
 	return &d
 }
@@ -0,0 +1,168 @@
+package data
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+func TestEndedGroupRejectsChanges(t *testing.T) {
+	red := user.Group{Name: "red"}
+	d := NewData()
+	d.SetHints(3, 0)
+	if err := d.AddGroup(red, []config.Game{testGame("CAt"), testGame("DOg")}, config.Passphrase{}); err != nil {
+		t.Fatal(err)
+	}
+	fill(t, d, red, "C")
+	if err := d.GroupEndAllGame(red); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		change  func() error
+		wantErr string
+	}{
+		{name: "insert key", change: func() error {
+			return d.GroupInsertKeyAt(red, key.Key{Char: key.Letters['A'], MustBe: key.Letters['A'], State: key.EDITABLE}, 0, 1)
+		}, wantErr: "GroupInsertKeyAt: group has ended"},
+		{name: "hint", change: func() error {
+			return d.GroupHint(red, [][2]int{{0, 1}})
+		}, wantErr: "GroupHint: group has ended"},
+		{name: "skip puzzle", change: func() error {
+			return d.GroupGotoNextGame(red)
+		}, wantErr: "GroupGotoNextGame: group has ended"},
+		{name: "end again", change: func() error {
+			return d.GroupEndAllGame(red)
+		}, wantErr: "GroupEndAllGame: group has already ended"},
+	}
+	for _, tt := range tests {
+		if err := tt.change(); fmt.Sprint(err) != tt.wantErr {
+			t.Errorf("%s: error = %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+	if got := row(t, d, red); got != "C  " {
+		t.Errorf("row = %q, want %q", got, "C  ")
+	}
+	if i, _ := d.GetGroupCurrentGameIndex(red); i != 0 {
+		t.Errorf("current game = %d, want 0", i)
+	}
+}
+
+func TestClockStopsAtDeadline(t *testing.T) {
+	red := user.Group{Name: "red"}
+	d := NewData()
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Millisecond)
+	d.SetSchedule(start, time.Now().Add(time.Hour))
+	if err := d.AddGroup(red, []config.Game{testGame("CAt")}, config.Passphrase{}); err != nil {
+		t.Fatal(err)
+	}
+	fill(t, d, red, "C")
+	d.SetSchedule(start, start.Add(time.Hour))
+
+	for _, item := range d.GetLeaderboard(red.Contest) {
+		if got := time.Duration(item.elapsed) * time.Millisecond; got != time.Hour {
+			t.Errorf("leaderboard elapsed = %v, want %v", got, time.Hour)
+		}
+	}
+	for _, st := range d.GetGroupStatuses() {
+		if st.Elapsed != time.Hour {
+			t.Errorf("status elapsed = %v, want %v", st.Elapsed, time.Hour)
+		}
+	}
+}
+
+func TestGetGroupRowColumnOutOfGrid(t *testing.T) {
+	red := user.Group{Name: "red"}
+	d := NewData()
+	if err := d.AddGroup(red, []config.Game{testGame("CAt")}, config.Passphrase{}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		row, col int
+		wantErr  bool
+	}{
+		{row: 0, col: 0},
+		{row: 0, col: 2},
+		{row: 0, col: 3, wantErr: true},
+		{row: 1, col: 0, wantErr: true},
+		{row: -1, col: 0, wantErr: true},
+		{row: 0, col: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := d.GetGroupRowColumn(red, tt.row, tt.col); (err != nil) != tt.wantErr {
+			t.Errorf("GetGroupRowColumn(%d, %d) error = %v, want error %v", tt.row, tt.col, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGroupItemLess(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b GroupItem
+		want bool
+	}{
+		{name: "finished before unfinished", a: GroupItem{endTime: 2, elapsed: 900}, b: GroupItem{completed: 3, elapsed: 10}, want: true},
+		{name: "unfinished after finished", a: GroupItem{completed: 3, elapsed: 10}, b: GroupItem{endTime: 2, elapsed: 900}},
+		{name: "finished faster", a: GroupItem{endTime: 9, elapsed: 10}, b: GroupItem{endTime: 1, elapsed: 20}, want: true},
+		{name: "finished slower", a: GroupItem{endTime: 1, elapsed: 20}, b: GroupItem{endTime: 9, elapsed: 10}},
+		{name: "more games completed", a: GroupItem{completed: 2, elapsed: 90}, b: GroupItem{completed: 1, correct: 5, elapsed: 10}, want: true},
+		{name: "fewer games completed", a: GroupItem{completed: 1, correct: 5, elapsed: 10}, b: GroupItem{completed: 2, elapsed: 90}},
+		{name: "more keys correct", a: GroupItem{completed: 1, correct: 3, elapsed: 90}, b: GroupItem{completed: 1, correct: 2, elapsed: 10}, want: true},
+		{name: "less elapsed", a: GroupItem{correct: 2, elapsed: 10}, b: GroupItem{correct: 2, elapsed: 90}, want: true},
+		{name: "tie", a: GroupItem{correct: 2, elapsed: 10}, b: GroupItem{correct: 2, elapsed: 10}},
+	}
+	for _, tt := range tests {
+		if got := tt.a.less(tt.b); got != tt.want {
+			t.Errorf("%s: less() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLeaderboardAfterDeadline(t *testing.T) {
+	d := NewData()
+	d.SetHints(1, time.Minute)
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Millisecond)
+	d.SetSchedule(start, time.Now().Add(time.Hour))
+	groups := map[string]string{"red": "C", "blue": "CA", "green": "C", "yellow": "C"}
+	for name, word := range groups {
+		grp := user.Group{Name: name}
+		if err := d.AddGroup(grp, []config.Game{testGame("CAt")}, config.Passphrase{}); err != nil {
+			t.Fatal(err)
+		}
+		fill(t, d, grp, word)
+	}
+	// green catches up with blue by a hint, which costs a minute
+	if err := d.GroupHint(user.Group{Name: "green"}, [][2]int{{0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	// red ended two hours after the start, slower than the rest but finished
+	if err := d.GroupEndAllGame(user.Group{Name: "red"}); err != nil {
+		t.Fatal(err)
+	}
+	d.SetSchedule(start, start.Add(time.Hour))
+
+	want := []struct {
+		name    string
+		elapsed time.Duration
+	}{
+		{name: "red"},
+		{name: "blue", elapsed: time.Hour},
+		{name: "green", elapsed: time.Hour + time.Minute},
+		{name: "yellow", elapsed: time.Hour},
+	}
+	l := d.GetLeaderboard("")
+	if len(l) != len(want) {
+		t.Fatalf("leaderboard has %d groups, want %d", len(l), len(want))
+	}
+	for i, w := range want {
+		elapsed := time.Duration(l[i].elapsed) * time.Millisecond
+		if l[i].groupName != w.name || w.elapsed != 0 && elapsed != w.elapsed {
+			t.Errorf("place %d = %s in %v, want %s in %v", i+1, l[i].groupName, elapsed, w.name, w.elapsed)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package data
 
 import (
+	"time"
+
 	"github.com/amirkhaki/crossword/config"
 	"github.com/amirkhaki/crossword/key"
 	"github.com/amirkhaki/crossword/user"
 )
 
+// package level functions are a thin wrapper over a default Data, kept for
+// compatibility. new code should get a GameService explicitly
 var d *Data
 
 func init() {
@@ -14,6 +18,11 @@ func init() {
 	}
 }
 
+// Default returns the Data package level functions work on
+func Default() *Data {
+	return d
+}
+
 func GroupAllGameEnded(grp user.Group) (bool, error) {
 	return d.GroupAllGameEnded(grp)
 }
@@ -26,8 +35,40 @@ func GroupIsPassphraseCorrect(grp user.Group, passphrase string) (bool, error) {
 	return d.GroupIsPassphraseCorrect(grp, passphrase)
 }
 
-func GetItems() []GroupItem {
-	return d.GetItems()
+func GetItems(contest string) []GroupItem {
+	return d.GetItems(contest)
+}
+
+func GetLeaderboard(contest string) []GroupItem {
+	return d.GetLeaderboard(contest)
+}
+
+func GetGroupSplits(grp user.Group) ([]Split, error) {
+	return d.GetGroupSplits(grp)
+}
+
+func GetGroupInventory(grp user.Group) (Inventory, error) {
+	return d.GetGroupInventory(grp)
+}
+
+func GetGroupAttempts(grp user.Group) (Attempts, error) {
+	return d.GetGroupAttempts(grp)
+}
+
+func SetPassphrasePenalties(cooldown, penalty time.Duration) {
+	d.SetPassphrasePenalties(cooldown, penalty)
+}
+
+func GetGroupHints(grp user.Group) (Hints, error) {
+	return d.GetGroupHints(grp)
+}
+
+func GroupHint(grp user.Group, cells [][2]int) error {
+	return d.GroupHint(grp, cells)
+}
+
+func SetHints(limit int, penalty time.Duration) {
+	d.SetHints(limit, penalty)
 }
 
 func IsAfterGame(grp user.Group) (bool, error) {
@@ -48,11 +89,15 @@ func GetGroupRowColumn(grp user.Group, row, col int) (k key.Key, err error) {
 func GetGroupQuestions(grp user.Group) ([]string, error) {
 	return d.GetGroupQuestions(grp)
 }
+func GetGroupClues(grp user.Group) ([]config.Clue, error) {
+	return d.GetGroupClues(grp)
+}
+
 func GroupInsertKeyAt(grp user.Group, k key.Key, row, col int) (err error) {
 	return d.GroupInsertKeyAt(grp, k, row, col)
 }
 
-func AddGroup(grp user.Group, cfgs []config.Game, ps string) error {
+func AddGroup(grp user.Group, cfgs []config.Game, ps config.Passphrase) error {
 	return d.AddGroup(grp, cfgs, ps)
 }
 
@@ -70,3 +115,67 @@ func GetGroupInitialCol(grp user.Group) (int, error) {
 func GroupGameEnded(grp user.Group) (bool, error) {
 	return d.GroupGameEnded(grp)
 }
+
+func GetGroupCurrentGameIndex(grp user.Group) (int, error) {
+	return d.GetGroupCurrentGameIndex(grp)
+}
+
+func GroupSubscribe(grp user.Group, s Subscriber) {
+	d.GroupSubscribe(grp, s)
+}
+
+func Unsubscribe(s Subscriber) {
+	d.Unsubscribe(s)
+}
+
+func GroupSetCursor(grp user.Group, session int, username string, row, col int) error {
+	return d.GroupSetCursor(grp, session, username, row, col)
+}
+
+func GroupRemoveCursor(grp user.Group, session int) error {
+	return d.GroupRemoveCursor(grp, session)
+}
+
+func GetGroupCursors(grp user.Group) ([]Cursor, error) {
+	return d.GetGroupCursors(grp)
+}
+
+func GetGroupStatuses() []GroupStatus {
+	return d.GetGroupStatuses()
+}
+
+func GroupReset(grp user.Group) error {
+	return d.GroupReset(grp)
+}
+
+func SetSchedule(start, end time.Time) {
+	d.SetSchedule(start, end)
+}
+
+func GetStartTime() time.Time {
+	return d.GetStartTime()
+}
+
+func GetEndTime() time.Time {
+	return d.GetEndTime()
+}
+
+func ContestStarted() bool {
+	return d.ContestStarted()
+}
+
+func ContestEnded() bool {
+	return d.ContestEnded()
+}
+
+func Save(path string) error {
+	return d.Save(path)
+}
+
+func Restore(path string) error {
+	return d.Restore(path)
+}
+
+func AutoSave(path string, interval time.Duration) (stop func()) {
+	return d.AutoSave(path, interval)
+}
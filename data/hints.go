@@ -0,0 +1,115 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+type HintLimitError error
+
+// ErrNothingToReveal is returned by GroupHint when every given cell is
+// already correct
+var ErrNothingToReveal = errors.New("GroupHint: nothing to reveal")
+
+// Hints are hints a group used and may use
+type Hints struct {
+	Used  int
+	Limit int
+}
+
+// Left returns number of hints group may still use
+func (h Hints) Left() int {
+	if h.Used >= h.Limit {
+		return 0
+	}
+	return h.Limit - h.Used
+}
+
+// SetHints sets number of hints each group may use and time added to the
+// group for each hint, a zero limit disables hints
+func (d *Data) SetHints(limit int, penalty time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hintLimit = limit
+	d.hintPenalty = penalty
+}
+
+func (d *Data) GetGroupHints(grp user.Group) (h Hints, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GetGroupHints: Group not found"))
+		return
+	}
+
+	return Hints{Used: g.hints, Limit: d.hintLimit}, nil
+}
+
+// GroupHint reveals MustBe of the given cells of current game, cells are
+// rows and columns. every revealed cell uses a hint, cells already correct
+// are left alone and cells beyond the hints left are not revealed
+func (d *Data) GroupHint(grp user.Group, cells [][2]int) (err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GroupHint: Group not found"))
+		return
+	}
+
+	if g.hints >= d.hintLimit {
+		err = HintLimitError(fmt.Errorf("GroupHint: no hints left"))
+		return
+	}
+	if !d.contestStarted() {
+		err = ContestNotStartedError(fmt.Errorf("GroupHint: contest has not started"))
+		return
+	}
+	if d.contestEnded() {
+		err = ContestEndedError(fmt.Errorf("GroupHint: contest has ended"))
+		return
+	}
+	if g.endTime != 0 {
+		err = GroupEndedError(fmt.Errorf("GroupHint: group has ended"))
+		return
+	}
+	if g.isAfterGame {
+		err = fmt.Errorf("GroupHint: game is already solved")
+		return
+	}
+
+	st := g.states[g.currentGameIndex]
+	for _, c := range cells {
+		row, col := c[0], c[1]
+		if !st.isValidKey(row, col) {
+			err = fmt.Errorf("GroupHint: invalid row col: %d, %d", row, col)
+			return
+		}
+	}
+	revealed := 0
+	for _, c := range cells {
+		row, col := c[0], c[1]
+		k := st.actual[row][col]
+		if k.State == key.READONLY || k.Char == k.MustBe {
+			continue
+		}
+		if g.hints >= d.hintLimit {
+			break
+		}
+		k.Char = k.MustBe
+		d.setKey(&g, k, row, col)
+		g.hints++
+		revealed++
+	}
+	if revealed == 0 {
+		return ErrNothingToReveal
+	}
+	d.games[grp] = g
+	d.notify(grp)
+	return nil
+}
@@ -0,0 +1,116 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+// row returns letters of current game of grp, a space for empty cells and
+// # for readonly ones
+func row(t *testing.T, d *Data, grp user.Group) string {
+	t.Helper()
+	cols, err := d.GetGroupCols(grp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l []rune
+	for col := 0; col < cols; col++ {
+		k, err := d.GetGroupRowColumn(grp, 0, col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.State == key.READONLY {
+			l = append(l, '#')
+			continue
+		}
+		l = append(l, rune(k.Char))
+	}
+	return string(l)
+}
+
+func TestGroupHint(t *testing.T) {
+	red := user.Group{Name: "red"}
+	word := [][2]int{{0, 0}, {0, 1}, {0, 2}}
+	tests := []struct {
+		name     string
+		game     string
+		limit    int
+		schedule time.Time
+		fill     string
+		cells    [][2]int
+		wantErr  error
+		wantUsed int
+		wantRow  string
+	}{
+		{name: "hints disabled", game: "CAt", cells: word[:1], wantErr: errors.New("GroupHint: no hints left"), wantRow: "   "},
+		{name: "letter", game: "CAt", limit: 3, cells: word[1:2], wantUsed: 1, wantRow: " A "},
+		{name: "word", game: "CAt", limit: 3, cells: word, wantUsed: 3, wantRow: "CAT"},
+		{name: "a hint per wrong or empty letter", game: "CAt", limit: 3, fill: "CX", cells: word, wantUsed: 2, wantRow: "CAT"},
+		{name: "stops at the limit", game: "CAt", limit: 2, cells: word, wantUsed: 2, wantRow: "CA "},
+		{name: "nothing to reveal", game: "CAt", limit: 3, fill: "CA", cells: word[:2], wantErr: ErrNothingToReveal, wantRow: "CA "},
+		{name: "readonly cell", game: "D#oG", limit: 3, cells: [][2]int{{0, 1}}, wantErr: ErrNothingToReveal, wantRow: " #  "},
+		{name: "invalid cell", game: "CAt", limit: 3, cells: [][2]int{{0, 0}, {0, 3}}, wantErr: errors.New("GroupHint: invalid row col: 0, 3"), wantRow: "   "},
+		{name: "solved game", game: "CAt", limit: 3, fill: "CAT", cells: word[:1], wantErr: errors.New("GroupHint: game is already solved"), wantRow: "CAT"},
+		{name: "contest not started", game: "CAt", limit: 3, schedule: time.Now().Add(time.Hour), cells: word[:1],
+			wantErr: errors.New("GroupHint: contest has not started"), wantRow: "   "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewData()
+			d.SetHints(tt.limit, time.Minute)
+			if err := d.AddGroup(red, []config.Game{testGame(tt.game)}, config.Passphrase{}); err != nil {
+				t.Fatal(err)
+			}
+			fill(t, d, red, tt.fill)
+			d.SetSchedule(tt.schedule, time.Time{})
+
+			err := d.GroupHint(red, tt.cells)
+			if tt.wantErr == ErrNothingToReveal && !errors.Is(err, ErrNothingToReveal) ||
+				fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Errorf("GroupHint() error = %v, want %v", err, tt.wantErr)
+			}
+
+			h, err := d.GetGroupHints(red)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Used != tt.wantUsed || h.Left() != tt.limit-tt.wantUsed {
+				t.Errorf("hints = %+v, want %d used of %d", h, tt.wantUsed, tt.limit)
+			}
+			if got := row(t, d, red); got != tt.wantRow {
+				t.Errorf("row = %q, want %q", got, tt.wantRow)
+			}
+			if got, want := penaltyOf(t, d, red), time.Duration(tt.wantUsed)*time.Minute; got != want {
+				t.Errorf("penalty = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGroupHintSolvesGame(t *testing.T) {
+	red := user.Group{Name: "red"}
+	d := NewData()
+	d.SetHints(3, 0)
+	if err := d.AddGroup(red, []config.Game{testGame("CAt")}, config.Passphrase{}); err != nil {
+		t.Fatal(err)
+	}
+	fill(t, d, red, "CA")
+	if err := d.GroupHint(red, [][2]int{{0, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := d.GroupIsAfterGame(red); !after {
+		t.Error("game revealed by a hint is not solved")
+	}
+	if inv, _ := d.GetGroupInventory(red); inv.String() != "T" {
+		t.Errorf("inventory = %q, want %q", inv, "T")
+	}
+	if err := d.GroupHint(red, [][2]int{{0, 0}}); err == nil {
+		t.Error("GroupHint() on a solved game succeeded")
+	}
+}
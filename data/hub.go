@@ -0,0 +1,77 @@
+package data
+
+import (
+	"sync"
+
+	"github.com/amirkhaki/crossword/user"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Subscriber receives messages about changes in a group, Send is called
+// with the lock of Data held so it must not block
+type Subscriber interface {
+	Send(tea.Msg)
+}
+
+// GroupChangedMsg is sent to every subscriber of a group whenever state of
+// the group changes
+type GroupChangedMsg struct {
+	Group user.Group
+}
+
+type hub struct {
+	mu   sync.Mutex
+	subs map[user.Group]map[Subscriber]struct{}
+}
+
+func newHub() *hub {
+	h := hub{}
+	h.subs = make(map[user.Group]map[Subscriber]struct{})
+	return &h
+}
+
+func (h *hub) subscribe(grp user.Group, s Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[grp]; !ok {
+		h.subs[grp] = make(map[Subscriber]struct{})
+	}
+	h.subs[grp][s] = struct{}{}
+}
+
+func (h *hub) unsubscribe(s Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for grp, subs := range h.subs {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(h.subs, grp)
+		}
+	}
+}
+
+// publish sends msg to every subscriber of grp
+func (h *hub) publish(grp user.Group, msg tea.Msg) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs[grp] {
+		s.Send(msg)
+	}
+}
+
+// GroupSubscribe registers s to receive GroupChangedMsg for grp
+func (d *Data) GroupSubscribe(grp user.Group, s Subscriber) {
+	d.hub.subscribe(grp, s)
+}
+
+// Unsubscribe removes s from every group it is subscribed to
+func (d *Data) Unsubscribe(s Subscriber) {
+	d.hub.unsubscribe(s)
+}
+
+// notify must be called with d.mu held after every change of grp
+func (d *Data) notify(grp user.Group) {
+	d.version++
+	d.hub.publish(grp, GroupChangedMsg{Group: grp})
+}
@@ -0,0 +1,99 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+// PassphraseLetter is a passphrase cell of a game, Letter is zero until the
+// group collects it by solving the game
+type PassphraseLetter struct {
+	Game   int
+	Row    int
+	Col    int
+	Letter rune
+}
+
+// Inventory is passphrase cells of every game of a group in the order they
+// are read in the passphrase
+type Inventory struct {
+	Letters []PassphraseLetter
+}
+
+// Collected returns number of letters the group collected
+func (inv Inventory) Collected() (n int) {
+	for _, l := range inv.Letters {
+		if l.Letter != 0 {
+			n++
+		}
+	}
+	return
+}
+
+// String returns letters separated by spaces, with an underscore for each
+// letter still to be found
+func (inv Inventory) String() string {
+	l := make([]string, len(inv.Letters))
+	for i, c := range inv.Letters {
+		l[i] = "_"
+		if c.Letter != 0 {
+			l[i] = string(c.Letter)
+		}
+	}
+	return strings.Join(l, " ")
+}
+
+// passphraseCells returns passphrase letters of g in the order of rule, the
+// same order config.DerivePassphrase reads them in
+func (g gameState) passphraseCells(game int, rule config.PassphraseRule) (l []PassphraseLetter) {
+	outer, inner := g.rows, g.cols
+	if rule == config.PassphraseCols {
+		outer, inner = g.cols, g.rows
+	}
+	for i := 0; i < outer; i++ {
+		for j := 0; j < inner; j++ {
+			row, col := i, j
+			if rule == config.PassphraseCols {
+				row, col = j, i
+			}
+			if k := g.actual[row][col]; k.State == key.PASSPHRASE {
+				l = append(l, PassphraseLetter{Game: game, Row: row, Col: col, Letter: rune(k.MustBe)})
+			}
+		}
+	}
+	return
+}
+
+// collect records passphrase letters of game i of g once it is solved
+func (g *groupState) collect(i int) {
+	g.collected = append(g.collected, g.states[i].passphraseCells(i, g.passphrase.Rule)...)
+}
+
+// GetGroupInventory returns passphrase letters of grp in passphrase order,
+// letters of games grp hasn't solved, like the ones skipped by an admin, are
+// left zero
+func (d *Data) GetGroupInventory(grp user.Group) (inv Inventory, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	g, ok := d.games[grp]
+	if !ok {
+		err = GroupNotFoundError(fmt.Errorf("GetGroupInventory: Group not found"))
+		return
+	}
+
+	collected := make(map[[3]int]rune)
+	for _, c := range g.collected {
+		collected[[3]int{c.Game, c.Row, c.Col}] = c.Letter
+	}
+	for i, st := range g.states {
+		for _, c := range st.passphraseCells(i, g.passphrase.Rule) {
+			c.Letter = collected[[3]int{c.Game, c.Row, c.Col}]
+			inv.Letters = append(inv.Letters, c)
+		}
+	}
+	return
+}
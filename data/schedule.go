@@ -0,0 +1,69 @@
+package data
+
+import (
+	"time"
+)
+
+// SetSchedule sets a fixed start and end for the contest, zero values mean
+// no limit. every group is notified when contest starts and ends
+func (d *Data) SetSchedule(start, end time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.start = start
+	d.end = end
+	for _, t := range []time.Time{start, end} {
+		if t.IsZero() || !time.Now().Before(t) {
+			continue
+		}
+		time.AfterFunc(time.Until(t), d.notifyAll)
+	}
+}
+
+func (d *Data) GetStartTime() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.start
+}
+
+func (d *Data) GetEndTime() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.end
+}
+
+func (d *Data) ContestStarted() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.contestStarted()
+}
+
+func (d *Data) ContestEnded() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.contestEnded()
+}
+
+func (d *Data) contestStarted() bool {
+	return d.start.IsZero() || !time.Now().Before(d.start)
+}
+
+func (d *Data) contestEnded() bool {
+	return !d.end.IsZero() && !time.Now().Before(d.end)
+}
+
+// now returns current time in milliseconds, clocks of unfinished groups
+// stop at the end of the contest
+func (d *Data) now() int64 {
+	if d.contestEnded() {
+		return d.end.UnixMilli()
+	}
+	return time.Now().UnixMilli()
+}
+
+func (d *Data) notifyAll() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for grp := range d.games {
+		d.notify(grp)
+	}
+}
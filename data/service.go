@@ -0,0 +1,51 @@
+package data
+
+import (
+	"time"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+// GameService is the game state models work with, *Data implements it
+type GameService interface {
+	AddGroup(grp user.Group, cfgs []config.Game, ps config.Passphrase) error
+	GroupAllGameEnded(grp user.Group) (bool, error)
+	GroupEndAllGame(grp user.Group) error
+	GroupIsPassphraseCorrect(grp user.Group, passphrase string) (bool, error)
+	GroupIsAfterGame(grp user.Group) (bool, error)
+	GroupGameEnded(grp user.Group) (bool, error)
+	GroupInsertKeyAt(grp user.Group, k key.Key, row, col int) error
+	GroupGotoNextGame(grp user.Group) error
+	GroupReset(grp user.Group) error
+	GroupHint(grp user.Group, cells [][2]int) error
+	GroupSetCursor(grp user.Group, session int, username string, row, col int) error
+	GroupRemoveCursor(grp user.Group, session int) error
+	GroupSubscribe(grp user.Group, s Subscriber)
+	Unsubscribe(s Subscriber)
+
+	GetItems(contest string) []GroupItem
+	GetLeaderboard(contest string) []GroupItem
+	GetGroupStatuses() []GroupStatus
+	GetGroupRows(grp user.Group) (int, error)
+	GetGroupCols(grp user.Group) (int, error)
+	GetGroupRowColumn(grp user.Group, row, col int) (key.Key, error)
+	GetGroupQuestions(grp user.Group) ([]string, error)
+	GetGroupClues(grp user.Group) ([]config.Clue, error)
+	GetGroupInitialRow(grp user.Group) (int, error)
+	GetGroupInitialCol(grp user.Group) (int, error)
+	GetGroupCurrentGameIndex(grp user.Group) (int, error)
+	GetGroupCursors(grp user.Group) ([]Cursor, error)
+	GetGroupSplits(grp user.Group) ([]Split, error)
+	GetGroupInventory(grp user.Group) (Inventory, error)
+	GetGroupAttempts(grp user.Group) (Attempts, error)
+	GetGroupHints(grp user.Group) (Hints, error)
+
+	GetStartTime() time.Time
+	GetEndTime() time.Time
+	ContestStarted() bool
+	ContestEnded() bool
+}
+
+var _ GameService = (*Data)(nil)
@@ -0,0 +1,242 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+type gameSnapshot struct {
+	// Chars has a string per row, holding char of every key
+	Chars     []string `json:"chars"`
+	StartTime int64    `json:"start_time"`
+	EndTime   int64    `json:"end_time"`
+}
+
+// cellSnapshot is a collected passphrase letter, the letter itself comes
+// from config
+type cellSnapshot struct {
+	Game int `json:"game"`
+	Row  int `json:"row"`
+	Col  int `json:"col"`
+}
+
+type groupSnapshot struct {
+	Group            user.Group     `json:"group"`
+	CurrentGameIndex int            `json:"current_game_index"`
+	IsAfterGame      bool           `json:"is_after_game"`
+	StartTime        int64          `json:"start_time"`
+	EndTime          int64          `json:"end_time"`
+	Started          bool           `json:"started"`
+	Attempts         int            `json:"attempts"`
+	WrongAttempts    int            `json:"wrong_attempts"`
+	RetryAt          int64          `json:"retry_at"`
+	Hints            int            `json:"hints"`
+	Collected        []cellSnapshot `json:"collected"`
+	Games            []gameSnapshot `json:"games"`
+}
+
+type snapshot struct {
+	Groups []groupSnapshot `json:"groups"`
+}
+
+func (d *Data) snapshot() snapshot {
+	var s snapshot
+	for grp, g := range d.games {
+		gs := groupSnapshot{
+			Group:            grp,
+			CurrentGameIndex: g.currentGameIndex,
+			IsAfterGame:      g.isAfterGame,
+			StartTime:        g.startTime,
+			EndTime:          g.endTime,
+			Started:          g.started,
+			Attempts:         g.attempts,
+			WrongAttempts:    g.wrongAttempts,
+			RetryAt:          g.retryAt,
+			Hints:            g.hints,
+		}
+		for _, c := range g.collected {
+			gs.Collected = append(gs.Collected, cellSnapshot{Game: c.Game, Row: c.Row, Col: c.Col})
+		}
+		for _, st := range g.states {
+			game := gameSnapshot{StartTime: st.startTime, EndTime: st.endTime}
+			for _, row := range st.actual {
+				chars := make([]rune, len(row))
+				for j, k := range row {
+					chars[j] = rune(k.Char)
+				}
+				game.Chars = append(game.Chars, string(chars))
+			}
+			gs.Games = append(gs.Games, game)
+		}
+		s.Groups = append(s.Groups, gs)
+	}
+	return s
+}
+
+// Save writes progress of every group to path. file is replaced atomically
+// so a crash while saving keeps the previous snapshot
+func (d *Data) Save(path string) error {
+	d.mu.Lock()
+	s := d.snapshot()
+	d.mu.Unlock()
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("Save: %w", err)
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("Save: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+	return nil
+}
+
+// Restore loads progress saved by Save into groups already added. groups
+// that are unknown or whose games do not match the config are skipped. a
+// missing file is not an error
+func (d *Data) Restore(path string) error {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Restore: %w", err)
+	}
+	var s snapshot
+	if err = json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("Restore: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, gs := range s.Groups {
+		g, ok := d.games[gs.Group]
+		if !ok {
+			log.Printf("Restore: skipping unknown group %s", gs.Group.Name)
+			continue
+		}
+		if err := g.restore(gs); err != nil {
+			log.Printf("Restore: skipping group %s: %s", gs.Group.Name, err)
+			continue
+		}
+		d.games[gs.Group] = g
+	}
+	return nil
+}
+
+func (g *groupState) restore(gs groupSnapshot) error {
+	if len(gs.Games) != len(g.states) || gs.CurrentGameIndex >= len(g.states) {
+		return fmt.Errorf("saved %d games, config has %d", len(gs.Games), len(g.states))
+	}
+	states := make([]gameState, len(g.states))
+	for i, game := range gs.Games {
+		st := newGameState(g.cfgs[i])
+		if len(game.Chars) != st.rows {
+			return fmt.Errorf("game %d: saved %d rows, config has %d", i, len(game.Chars), st.rows)
+		}
+		for row, chars := range game.Chars {
+			runes := []rune(chars)
+			if len(runes) != st.cols {
+				return fmt.Errorf("game %d: saved %d cols, config has %d", i, len(runes), st.cols)
+			}
+			for col, r := range runes {
+				if char, ok := key.Letters[r]; ok && st.actual[row][col].State != key.READONLY {
+					st.actual[row][col].Char = char
+				}
+			}
+		}
+		st.startTime = game.StartTime
+		st.endTime = game.EndTime
+		states[i] = st
+	}
+	g.states = states
+	g.currentGameIndex = gs.CurrentGameIndex
+	g.isAfterGame = gs.IsAfterGame
+	g.startTime = gs.StartTime
+	g.endTime = gs.EndTime
+	g.started = gs.Started
+	g.attempts = gs.Attempts
+	g.wrongAttempts = gs.WrongAttempts
+	g.retryAt = gs.RetryAt
+	g.hints = gs.Hints
+	g.collected = nil
+	if gs.Collected == nil {
+		// saved before collected letters were recorded
+		for i, st := range g.states {
+			if st.ended() {
+				g.collect(i)
+			}
+		}
+		return nil
+	}
+	for _, c := range gs.Collected {
+		if c.Game < 0 || c.Game >= len(g.states) || !g.states[c.Game].isValidKey(c.Row, c.Col) ||
+			g.states[c.Game].actual[c.Row][c.Col].State != key.PASSPHRASE {
+			return fmt.Errorf("collected letter at game %d, cell %d, %d is not a passphrase cell", c.Game, c.Row, c.Col)
+		}
+		k := g.states[c.Game].actual[c.Row][c.Col]
+		g.collected = append(g.collected, PassphraseLetter{Game: c.Game, Row: c.Row, Col: c.Col, Letter: rune(k.MustBe)})
+	}
+	return nil
+}
+
+// AutoSave saves to path every interval if anything has changed since last
+// save. calling stop saves one last time and stops saving
+func (d *Data) AutoSave(path string, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	save := func(last uint64) uint64 {
+		d.mu.Lock()
+		version := d.version
+		d.mu.Unlock()
+		if version == last {
+			return last
+		}
+		if err := d.Save(path); err != nil {
+			log.Println(err)
+			return last
+		}
+		return version
+	}
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last uint64
+		for {
+			select {
+			case <-ticker.C:
+				last = save(last)
+			case <-done:
+				save(last)
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
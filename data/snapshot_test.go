@@ -0,0 +1,193 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/key"
+	"github.com/amirkhaki/crossword/user"
+)
+
+// testGame returns a single row game whose answer is word, upper case
+// letters are editable cells, lower case letters are passphrase cells and #
+// is a readonly cell
+func testGame(word string) config.Game {
+	var g config.Game
+	g.Rows, g.Cols = 1, len([]rune(word))
+	for col, r := range []rune(word) {
+		k := key.Key{Char: key.EMPTY, MustBe: key.EMPTY, State: key.READONLY}
+		if r != '#' {
+			k = key.Key{Char: key.EMPTY, MustBe: key.Letters[unicode.ToUpper(r)], State: key.EDITABLE}
+			if unicode.IsLower(r) {
+				k.State = key.PASSPHRASE
+			}
+		}
+		g.Actual.Keys = append(g.Actual.Keys, config.GridKey{Row: 0, Col: col, Key: k})
+	}
+	return g
+}
+
+// fill types letters of word into current game of grp, skipping spaces
+func fill(t *testing.T, d *Data, grp user.Group, word string) {
+	t.Helper()
+	for col, r := range []rune(word) {
+		if r == ' ' || r == '#' {
+			continue
+		}
+		k, err := d.GetGroupRowColumn(grp, 0, col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k.Char = key.Letters[unicode.ToUpper(r)]
+		if err = d.GroupInsertKeyAt(grp, k, 0, col); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// snapshots returns snapshot of d keyed by group
+func snapshots(d *Data) map[user.Group]groupSnapshot {
+	m := make(map[user.Group]groupSnapshot)
+	for _, gs := range d.snapshot().Groups {
+		m[gs.Group] = gs
+	}
+	return m
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	red := user.Group{Name: "red"}
+	blue := user.Group{Contest: "finals", Name: "blue"}
+	games := []config.Game{testGame("CAt"), testGame("D#oG")}
+	tests := []struct {
+		name string
+		play func(t *testing.T, d *Data)
+	}{
+		{name: "nothing played", play: func(t *testing.T, d *Data) {}},
+		{name: "first game half filled", play: func(t *testing.T, d *Data) {
+			fill(t, d, red, "CA ")
+		}},
+		{name: "wrong letters", play: func(t *testing.T, d *Data) {
+			fill(t, d, blue, "XYZ")
+		}},
+		{name: "second game", play: func(t *testing.T, d *Data) {
+			fill(t, d, red, "CAT")
+			if err := d.GroupGotoNextGame(red); err != nil {
+				t.Fatal(err)
+			}
+			fill(t, d, red, "D#O ")
+		}},
+		{name: "skipped game", play: func(t *testing.T, d *Data) {
+			if err := d.GroupGotoNextGame(blue); err != nil {
+				t.Fatal(err)
+			}
+			fill(t, d, blue, "D#OG")
+		}},
+		{name: "all ended with attempts and hints", play: func(t *testing.T, d *Data) {
+			fill(t, d, red, "CAT")
+			d.GroupGotoNextGame(red)
+			if err := d.GroupHint(red, [][2]int{{0, 0}}); err != nil {
+				t.Fatal(err)
+			}
+			fill(t, d, red, "  OG")
+			d.GroupIsPassphraseCorrect(red, "no")
+			if err := d.GroupEndAllGame(red); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewData()
+			d.SetHints(3, 0)
+			for _, grp := range []user.Group{red, blue} {
+				if err := d.AddGroup(grp, games, config.Passphrase{Text: "to"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tt.play(t, d)
+			path := filepath.Join(t.TempDir(), "state.json")
+			if err := d.Save(path); err != nil {
+				t.Fatal(err)
+			}
+
+			restored := NewData()
+			for _, grp := range []user.Group{red, blue} {
+				restored.AddGroup(grp, games, config.Passphrase{Text: "to"})
+			}
+			if err := restored.Restore(path); err != nil {
+				t.Fatal(err)
+			}
+			want, got := snapshots(d), snapshots(restored)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("restored %+v, want %+v", got, want)
+			}
+			for _, grp := range []user.Group{red, blue} {
+				inv, _ := d.GetGroupInventory(grp)
+				restoredInv, _ := restored.GetGroupInventory(grp)
+				if inv.String() != restoredInv.String() {
+					t.Errorf("%s: restored inventory %q, want %q", grp.Name, restoredInv, inv)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreSkipsMismatchedGroups(t *testing.T) {
+	red := user.Group{Name: "red"}
+	d := NewData()
+	d.AddGroup(red, []config.Game{testGame("CAT"), testGame("DOG")}, config.Passphrase{})
+	fill(t, d, red, "CA ")
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := d.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		games []config.Game
+	}{
+		{name: "fewer games", games: []config.Game{testGame("CAT")}},
+		{name: "other size", games: []config.Game{testGame("CATS"), testGame("DOG")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := NewData()
+			restored.AddGroup(red, tt.games, config.Passphrase{})
+			want := snapshots(restored)
+			if err := restored.Restore(path); err != nil {
+				t.Fatal(err)
+			}
+			if got := snapshots(restored); !reflect.DeepEqual(got, want) {
+				t.Errorf("mismatched group was restored: %+v", got)
+			}
+		})
+	}
+}
+
+func TestRestoreFile(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing.json")},
+		{name: "corrupt file", path: corrupt, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewData().Restore(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Restore() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package key
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -110,6 +111,31 @@ func (k Key) Render(color lipgloss.Color) string {
 		Render(string(k.Char))
 }
 
+// RenderNumbered renders the key like Render, with number drawn in the
+// top left corner of its border
+func (k Key) RenderNumbered(color lipgloss.Color, number int) string {
+	if k.State == READONLY || number <= 0 {
+		return k.Render(color)
+	}
+	border := lipgloss.NormalBorder()
+	body := lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(border).
+		BorderTop(false).
+		BorderForeground(color).
+		Foreground(color).
+		Render(string(k.Char))
+	width := lipgloss.Width(body) - 2
+	n := strconv.Itoa(number)
+	if len(n) > width {
+		n = n[:width]
+	}
+	top := lipgloss.NewStyle().
+		Foreground(color).
+		Render(border.TopLeft + n + strings.Repeat(border.Top, width-len(n)) + border.TopRight)
+	return lipgloss.JoinVertical(lipgloss.Left, top, body)
+}
+
 func (k Key) MustRender(color lipgloss.Color) string {
 	if k.State == READONLY {
 		return lipgloss.NewStyle().
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -14,11 +15,15 @@ import (
 	"github.com/amirkhaki/crossword/data"
 	"github.com/amirkhaki/crossword/model"
 	"github.com/amirkhaki/crossword/storage"
+	"github.com/amirkhaki/crossword/user"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	bm "github.com/charmbracelet/wish/bubbletea"
 	lm "github.com/charmbracelet/wish/logging"
-	"github.com/gliderlabs/ssh"
+	"github.com/muesli/termenv"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 var withServer *bool
@@ -26,19 +31,50 @@ var configPath *string
 var serverHost *string
 var serverPort *int
 
-func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+func programHandler(s ssh.Session) *tea.Program {
 	pty, _, active := s.Pty()
 	if !active {
 		wish.Fatalln(s, "no active terminal, skipping")
-		return nil, nil
+		return nil
 	}
 
-	// g, err := model.NewGame(cfg, pty.Window.Height, pty.Window.Width)
-	// if err != nil {
-	// 	log.Fatal(err)
-	// }
-	l := model.NewLogin(cfg, pty.Window.Height, pty.Window.Width)
-	return l, []tea.ProgramOption{tea.WithAltScreen()}
+	l := model.NewLogin(cfg, data.Default(), storage.Store, pty.Window.Height, pty.Window.Width)
+	var m tea.Model = l
+	if key := s.PublicKey(); key != nil {
+		u, err := storage.Store.GetUserByKey(s.Context(), key)
+		if err == nil {
+			started, cmd := l.LoginAs(u)
+			m = loggedIn{Model: started, cmd: cmd}
+		}
+	}
+	p := tea.NewProgram(m, append(bm.MakeOptions(s), tea.WithAltScreen())...)
+	l.Attach(p)
+	s.Context().SetValue(detacherKey{}, l)
+	return p
+}
+
+// loggedIn is the model a session starts with when it is logged in before
+// its program runs, Init returns the command returned by login
+type loggedIn struct {
+	tea.Model
+	cmd tea.Cmd
+}
+
+func (l loggedIn) Init() tea.Cmd {
+	return l.cmd
+}
+
+type detacherKey struct{}
+
+// detachMiddleware runs right after the program of the session exits, it
+// detaches the program so that changes of its group are no longer sent to it
+func detachMiddleware(h ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		if d, ok := s.Context().Value(detacherKey{}).(interface{ Detach() }); ok {
+			d.Detach()
+		}
+		h(s)
+	}
 }
 
 var cfg config.Config
@@ -48,66 +84,178 @@ func init() {
 	withServer = flag.Bool("server", false, "whether run ssh server or not")
 	serverHost = flag.String("host", "127.0.0.1", "host for server")
 	serverPort = flag.Int("port", 2222, "port for server")
-	flag.Parse()
+	flag.Usage = usage
+}
+
+func setup() {
 	var err error
 	cfg, err = config.New(*configPath)
 	if err != nil {
 		log.Fatal(err)
 	}
-	storage.Store = storage.NewInmemory()
-	for _, usr := range cfg.Users {
-		err = storage.Store.AddUser(context.Background(), usr)
-		if err != nil {
-			log.Fatal(err)
+	data.SetSchedule(cfg.StartTime, cfg.EndTime)
+	data.SetPassphrasePenalties(time.Duration(cfg.Attempts.Cooldown)*time.Second,
+		time.Duration(cfg.Attempts.Penalty)*time.Second)
+	data.SetHints(cfg.Hints.Limit, time.Duration(cfg.Hints.Penalty)*time.Second)
+	storage.Store, err = storage.NewStorage(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	contests := make(map[string]config.Contest)
+	for _, contest := range cfg.AllContests() {
+		contests[contest.Name] = contest
+		for _, usr := range contest.Users {
+			if !user.IsHashed(usr.Password) {
+				log.Printf("password of %s is not hashed, use hash-password command to hash it", usr.Username)
+				usr.Password, err = user.HashPassword(usr.Password)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+			if usr.Admin && usr.Group.Name == "" {
+				// admins don't need to be in a group
+				if err = saveUser(context.Background(), usr); err != nil {
+					log.Fatal(err)
+				}
+				continue
+			}
+			err = storage.Store.AddGroup(context.Background(), usr.Group)
+			if err, ok := err.(storage.GroupExistsError); err != nil && !ok {
+				log.Fatal(err)
+			}
+			if err = saveUser(context.Background(), usr); err != nil {
+				log.Fatal(err)
+			}
 		}
-		err = data.AddGroup(usr.Group, cfg.Games, cfg.Passphrase)
+	}
+	// users stored by an earlier run play too, not only those of config
+	users, err := storage.Store.ListUsers(context.Background(), 0, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, usr := range users {
+		if usr.Admin && usr.Group.Name == "" {
+			continue
+		}
+		contest, ok := contests[usr.Group.Contest]
+		if !ok || len(contest.Games) == 0 {
+			log.Printf("contest %q of %s is not in config, %s can't play", usr.Group.Contest, usr.Username, usr.Username)
+			continue
+		}
+		err = data.AddGroup(usr.Group, contest.Games, contest.ExpectedPassphrase())
 		if err, ok := err.(data.GroupExistsError); err != nil && !ok {
 			log.Fatal(err)
 		}
 	}
-
+	if cfg.State.Path != "" {
+		if err = data.Restore(cfg.State.Path); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
-// having a map of [user][]program
-// any interaction in a game will be sent to all of programs(p.Send)
-// so state of game should not be saved in the model cause it is user specific
-// thinking about having a map[user]struct {[]program, state}
+// saveUser adds usr to storage, or updates it if it is stored by an earlier
+// run, so that password, group, keys and admin flag in config always win
+func saveUser(ctx context.Context, usr user.User) error {
+	_, err := storage.Store.GetUserByUsername(ctx, usr.Username)
+	if err != nil {
+		return storage.Store.AddUser(ctx, usr)
+	}
+	return storage.Store.UpdateUser(ctx, usr)
+}
 
 func main() {
-	if *withServer {
-		s, err := wish.NewServer(
-			wish.WithAddress(fmt.Sprintf("%s:%d", *serverHost, *serverPort)),
-			wish.WithHostKeyPath(".ssh/term_info_ed25519"),
-			wish.WithMiddleware(
-				bm.Middleware(teaHandler),
-				lm.Middleware(),
-			),
-		)
-		if err != nil {
-			log.Fatalln(err)
+	flag.Parse()
+	if flag.NArg() != 0 {
+		cmd, ok := commands[flag.Arg(0)]
+		if !ok {
+			flag.Usage()
+			os.Exit(2)
 		}
-		done := make(chan os.Signal, 1)
-		signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-		log.Printf("Starting SSH server on %s:%d", *serverHost, *serverPort)
-		go func() {
-			if err = s.ListenAndServe(); err != nil {
-				log.Fatalln(err)
-			}
-		}()
-
-		<-done
-		log.Println("Stopping SSH server")
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer func() { cancel() }()
-		if err := s.Shutdown(ctx); err != nil {
-			log.Fatalln(err)
+		if err := cmd.run(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
 		}
-
+		return
+	}
+	setup()
+	stop := func() {}
+	if cfg.State.Path != "" {
+		interval := time.Duration(cfg.State.Interval) * time.Second
+		if interval <= 0 {
+			interval = 5 * time.Second
+		}
+		stop = data.AutoSave(cfg.State.Path, interval)
+	}
+	var err error
+	if *withServer {
+		err = serve()
 	} else {
-		login := model.NewLogin(cfg, 0, 0)
-		p := tea.NewProgram(login)
-		if err := p.Start(); err != nil {
-			log.Fatal(err)
+		err = play()
+	}
+	// log.Fatal skips deferred calls, progress is saved before exiting
+	stop()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serve runs the ssh server until it fails or the process is interrupted
+func serve() error {
+	s, err := wish.NewServer(
+		wish.WithAddress(fmt.Sprintf("%s:%d", *serverHost, *serverPort)),
+		wish.WithHostKeyPath(".ssh/term_info_ed25519"),
+		// only keys of known users are accepted, others fall back to
+		// keyboard interactive and log in with password
+		wish.WithPublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
+			_, err := storage.Store.GetUserByKey(ctx, key)
+			return err == nil
+		}),
+		wish.WithKeyboardInteractiveAuth(func(ctx ssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+			// public key callback also runs for keys that are only offered,
+			// without proving they are owned, and leaves them in context.
+			// such a key must not skip the password form
+			ctx.SetValue(ssh.ContextKeyPublicKey, nil)
+			return true
+		}),
+		// first middleware runs last
+		wish.WithMiddleware(
+			detachMiddleware,
+			bm.MiddlewareWithProgramHandler(programHandler, termenv.ANSI256),
+			lm.Middleware(),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	// styles of models are global, every session gets the same colors
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	log.Printf("Starting SSH server on %s:%d", *serverHost, *serverPort)
+	failed := make(chan error, 1)
+	go func() {
+		if err := s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			failed <- err
 		}
+	}()
+
+	select {
+	case err = <-failed:
+		return err
+	case <-done:
 	}
+	log.Println("Stopping SSH server")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer func() { cancel() }()
+	return s.Shutdown(ctx)
+}
+
+// play runs the game in the terminal of the process
+func play() error {
+	login := model.NewLogin(cfg, data.Default(), storage.Store, 0, 0)
+	p := tea.NewProgram(login)
+	login.Attach(p)
+	defer login.Detach()
+	_, err := p.Run()
+	return err
 }
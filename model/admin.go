@@ -0,0 +1,171 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/amirkhaki/crossword/data"
+	"github.com/amirkhaki/crossword/user"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// adminAction is an action on the selected group waiting for confirmation
+type adminAction struct {
+	name string
+	run  func(data.GameService, user.Group) error
+}
+
+var adminActions = map[string]adminAction{
+	"r": {name: "reset", run: data.GameService.GroupReset},
+	"s": {name: "skip current puzzle of", run: data.GameService.GroupGotoNextGame},
+	"e": {name: "end all games of", run: data.GameService.GroupEndAllGame},
+}
+
+type admin struct {
+	svc      data.GameService
+	usr      user.User
+	width    int
+	height   int
+	selected int
+	pending  *adminAction
+	status   string
+	inited   bool
+}
+
+func (a admin) Init() tea.Cmd {
+	return nil
+}
+
+// statuses returns groups the admin manages, admins of a named contest only
+// see groups of that contest
+func (a admin) statuses() []data.GroupStatus {
+	l := a.svc.GetGroupStatuses()
+	if a.usr.Group.Contest == "" {
+		return l
+	}
+	var filtered []data.GroupStatus
+	for _, st := range l {
+		if st.Group.Contest == a.usr.Group.Contest {
+			filtered = append(filtered, st)
+		}
+	}
+	return filtered
+}
+
+func (a admin) selectedGroup() (user.Group, bool) {
+	statuses := a.statuses()
+	if a.selected >= len(statuses) {
+		return user.Group{}, false
+	}
+	return statuses[a.selected].Group, true
+}
+
+// onLastPuzzle reports whether grp has no puzzle left to skip to, such
+// groups are only done once they answer the passphrase or are ended
+func (a admin) onLastPuzzle(grp user.Group) bool {
+	for _, st := range a.statuses() {
+		if st.Group == grp {
+			return st.CurrentGameIndex+1 >= st.GameCount
+		}
+	}
+	return false
+}
+
+func (a admin) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if !a.inited {
+		a.inited = true
+		cmd = doTick()
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if a.pending != nil {
+			if msg.String() == "y" {
+				grp, ok := a.selectedGroup()
+				if ok {
+					if err := a.pending.run(a.svc, grp); err != nil {
+						a.status = "an error accured: " + err.Error()
+					} else {
+						a.status = fmt.Sprintf("done: %s %s", a.pending.name, grp.Name)
+					}
+				}
+			} else {
+				a.status = "canceled"
+			}
+			a.pending = nil
+			return a, cmd
+		}
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return a, tea.Quit
+		case "up", "k":
+			if a.selected > 0 {
+				a.selected--
+			}
+		case "down", "j":
+			if a.selected+1 < len(a.statuses()) {
+				a.selected++
+			}
+		default:
+			action, ok := adminActions[msg.String()]
+			grp, found := a.selectedGroup()
+			if ok && found && msg.String() == "s" && a.onLastPuzzle(grp) {
+				a.status = fmt.Sprintf("%s is on its last puzzle, press e to end its games", grp.Name)
+			} else if ok && found {
+				a.pending = &action
+				a.status = fmt.Sprintf("%s %s? press y to confirm", action.name, grp.Name)
+			}
+		}
+	case tea.WindowSizeMsg:
+		a.height = msg.Height
+		a.width = msg.Width
+	case tickMsg:
+		cmd = doTick()
+	}
+	return a, cmd
+}
+
+func (a admin) View() string {
+	header := fmt.Sprintf("  %-12s %-20s %-8s %-10s %-10s %s", "CONTEST", "GROUP", "PUZZLE", "PROGRESS", "ELAPSED", "STATE")
+	rows := []string{lipgloss.NewStyle().Bold(true).Render(header)}
+	for i, st := range a.statuses() {
+		state := "waiting"
+		if st.Ended {
+			state = "ended"
+		} else if st.Started {
+			state = "playing"
+		}
+		row := fmt.Sprintf("%-12s %-20s %-8s %-10s %-10s %s",
+			st.Group.Contest,
+			st.Group.Name,
+			fmt.Sprintf("%d/%d", st.CurrentGameIndex+1, st.GameCount),
+			fmt.Sprintf("%d/%d", st.Correct, st.Total),
+			st.Elapsed.Truncate(time.Second).String(),
+			state)
+		if i == a.selected {
+			row = lipgloss.NewStyle().Foreground(colorGreen).Render("> " + row)
+		} else {
+			row = "  " + row
+		}
+		rows = append(rows, row)
+	}
+	help := lipgloss.NewStyle().Foreground(colorSecondary).
+		Render(strings.Join([]string{"↑/↓ select", "r reset", "s skip puzzle", "e end games", "q quit"}, " • "))
+	rows = append(rows, "", help)
+	if a.status != "" {
+		rows = append(rows, a.status)
+	}
+	board := lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	return lipgloss.Place(a.width, a.height, lipgloss.Center, lipgloss.Center, board)
+}
+
+func newAdmin(svc data.GameService, height, width int, u user.User) admin {
+	return admin{svc: svc, height: height, width: width, usr: u}
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/amirkhaki/crossword/data"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// countdown is shown to players logged in before the scheduled start, next
+// is called to build the game once contest starts
+type countdown struct {
+	svc    data.GameService
+	height int
+	width  int
+	next   func(height, width int) (tea.Model, tea.Cmd)
+	inited bool
+}
+
+func (c countdown) Init() tea.Cmd {
+	return nil
+}
+
+func (c countdown) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if c.svc.ContestStarted() {
+		return c.next(c.height, c.width)
+	}
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return c, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		c.height = msg.Height
+		c.width = msg.Width
+	case tickMsg:
+		cmd = doTick()
+	}
+	if !c.inited {
+		c.inited = true
+		cmd = doTick()
+	}
+	return c, cmd
+}
+
+func (c countdown) View() string {
+	start := c.svc.GetStartTime()
+	remaining := time.Until(start).Truncate(time.Second) + time.Second
+	style := lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62"))
+	text := lipgloss.JoinVertical(lipgloss.Center,
+		fmt.Sprintf("Contest starts at %s", start.Format("15:04:05")),
+		lipgloss.NewStyle().Bold(true).Foreground(colorGreen).Render(remaining.String()))
+	return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, style.Render(text))
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/amirkhaki/crossword/data"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cursorColors are used to draw cursors of teammates
+var cursorColors = []lipgloss.Color{
+	lipgloss.Color("#e06c75"),
+	lipgloss.Color("#61afef"),
+	lipgloss.Color("#c678dd"),
+	lipgloss.Color("#56b6c2"),
+	lipgloss.Color("#d19a66"),
+	lipgloss.Color("#98c379"),
+}
+
+// teammate is cursor of another session and the color it is drawn in
+type teammate struct {
+	data.Cursor
+	color lipgloss.Color
+}
+
+// initials returns first letter of every word of username, or first letter
+// of username if it is a single word
+func initials(username string) string {
+	words := strings.FieldsFunc(username, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		b.WriteRune(unicode.ToUpper([]rune(w)[0]))
+	}
+	if b.Len() == 0 && username != "" {
+		b.WriteRune(unicode.ToUpper([]rune(username)[0]))
+	}
+	return b.String()
+}
+
+// teammates returns cursors of other sessions in group of g keyed by position.
+// colors go by order of usernames in the whole group, so every member sees
+// the same color for a user and no two of the first len(cursorColors) users
+// share one
+func (g *game) teammates() (map[[2]int]teammate, []teammate, error) {
+	cursors, err := g.svc.GetGroupCursors(g.usr.Group)
+	if err != nil {
+		return nil, nil, err
+	}
+	positions := make(map[[2]int]teammate)
+	var others []teammate
+	color := -1
+	for i, c := range cursors {
+		if i == 0 || c.Username != cursors[i-1].Username {
+			color++
+		}
+		if c.Session == g.session {
+			continue
+		}
+		t := teammate{Cursor: c, color: cursorColors[color%len(cursorColors)]}
+		others = append(others, t)
+		if _, ok := positions[[2]int{c.Row, c.Col}]; !ok {
+			positions[[2]int{c.Row, c.Col}] = t
+		}
+	}
+	return positions, others, nil
+}
+
+// legend lists initials and username of teammates in their cursor color
+func legend(teammates []teammate) string {
+	lines := make([]string, len(teammates))
+	for i, t := range teammates {
+		lines[i] = lipgloss.NewStyle().
+			Foreground(t.color).
+			Render(initials(t.Username) + " " + t.Username)
+	}
+	return strings.Join(lines, "  ")
+}
+
+// publishCursor shares position of the cursor with teammates if it has
+// changed since last time
+func (g *game) publishCursor() tea.Cmd {
+	if g.cursorPublished && g.publishedRow == g.crrntRow && g.publishedCol == g.crrntCol {
+		return nil
+	}
+	err := g.svc.GroupSetCursor(g.usr.Group, g.session, g.usr.Username, g.crrntRow, g.crrntCol)
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+	g.cursorPublished = true
+	g.publishedRow, g.publishedCol = g.crrntRow, g.crrntCol
+	return nil
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/data"
+	"github.com/amirkhaki/crossword/user"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const catGrid = `CAT
+A#O
+BEe
+
+across
+1. Feline
+3. Buzzer
+
+down
+1. Taxi
+2. Foot digit
+`
+
+var red = user.Group{Name: "red"}
+
+// newTestData returns data with group red playing the cat grid
+func newTestData(t *testing.T) *data.Data {
+	t.Helper()
+	g, err := config.ReadGrid(strings.NewReader(catGrid), "cat.grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := data.NewData()
+	if err = d.AddGroup(red, []config.Game{g}, config.Passphrase{}); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// newTestGame returns game of a new session of username in group red
+func newTestGame(t *testing.T, d *data.Data, username string) *game {
+	t.Helper()
+	g, err := newGame(d, config.Colors{}, 30, 100, user.User{Username: username, Group: red}, newProgram())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestTeammateColors(t *testing.T) {
+	d := newTestData(t)
+	var games []*game
+	for _, username := range []string{"carol", "alice", "bob", "alice"} {
+		g := newTestGame(t, d, username)
+		g.publishCursor()
+		games = append(games, g)
+	}
+
+	want := map[string]lipgloss.Color{"alice": cursorColors[0], "bob": cursorColors[1], "carol": cursorColors[2]}
+	for _, g := range games {
+		_, others, err := g.teammates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(others) != len(games)-1 {
+			t.Errorf("%s sees %d teammates, want %d", g.usr.Username, len(others), len(games)-1)
+		}
+		for _, o := range others {
+			if o.color != want[o.Username] {
+				t.Errorf("%s sees %s in %s, want %s", g.usr.Username, o.Username, o.color, want[o.Username])
+			}
+		}
+	}
+}
+
+func TestCursorAfterReset(t *testing.T) {
+	d := newTestData(t)
+	alice, bob := newTestGame(t, d, "alice"), newTestGame(t, d, "bob")
+	alice.publishCursor()
+	bob.publishCursor()
+	if err := d.GroupReset(red); err != nil {
+		t.Fatal(err)
+	}
+
+	bob.Update(data.GroupChangedMsg{})
+	_, others, err := alice.teammates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(others) != 1 || others[0].Username != "bob" {
+		t.Errorf("teammates of alice after reset = %+v, want bob", others)
+	}
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"log"
+	"sort"
 	"time"
 	"unicode"
 
@@ -27,9 +28,16 @@ const (
 type endGameMsg struct{}
 
 type endScreen struct {
-	height int
-	width  int
-	inited bool
+	svc data.GameService
+	// contest whose results are shown
+	contest string
+	// grp and restart are set for players, restart builds the game again
+	// once grp is playing again, like after an admin reset it
+	grp     user.Group
+	restart func(height, width int) (tea.Model, tea.Cmd)
+	height  int
+	width   int
+	inited  bool
 }
 
 func (_ endScreen) Init() tea.Cmd {
@@ -52,6 +60,10 @@ func (e endScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c":
 			cmd = tea.Quit
 		}
+	case data.GroupChangedMsg:
+		if e.restart != nil && playing(e.svc, e.grp) {
+			return e.restart(e.height, e.width)
+		}
 	case tickMsg:
 		cmd = doTick()
 	default:
@@ -69,7 +81,7 @@ func (e endScreen) View() string {
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("62"))
 	var rows []string
-	for _, v := range data.GetItems() {
+	for _, v := range e.svc.GetItems(e.contest) {
 		rows = append(rows, style.Render(fmt.Sprintf("%s\n%s", v.Title(), v.Desciption())))
 	}
 	return lipgloss.Place(e.width, e.height, lipgloss.Center, lipgloss.Center,
@@ -78,10 +90,31 @@ func (e endScreen) View() string {
 }
 
 type passphraseScreen struct {
-	width      int
-	height     int
-	usr        user.User
-	passphrase textinput.Model
+	svc         data.GameService
+	width       int
+	height      int
+	usr         user.User
+	passphrase  textinput.Model
+	letterColor lipgloss.Color
+	// status is shown under the input, like result of the last answer
+	status  string
+	restart func(height, width int) (tea.Model, tea.Cmd)
+}
+
+// playing reports whether grp is back in a game, like after an admin reset
+// it, so players waiting after the games must go back to the grid
+func playing(svc data.GameService, grp user.Group) bool {
+	ended, err := svc.GroupAllGameEnded(grp)
+	if err != nil || ended {
+		return false
+	}
+	after, err := svc.GroupIsAfterGame(grp)
+	return err == nil && !after
+}
+
+func (ps passphraseScreen) end() (tea.Model, tea.Cmd) {
+	return endScreen{svc: ps.svc, contest: ps.usr.Group.Contest, grp: ps.usr.Group, restart: ps.restart,
+		height: ps.height, width: ps.width}.Update(nil)
 }
 
 func (ps passphraseScreen) Init() tea.Cmd {
@@ -93,27 +126,48 @@ func (ps passphraseScreen) doResize(msg tea.WindowSizeMsg) passphraseScreen {
 	ps.width = msg.Width
 	return ps
 }
+
+// waiting reports whether group is in cooldown after a wrong passphrase
+func (ps passphraseScreen) waiting() bool {
+	a, err := ps.svc.GetGroupAttempts(ps.usr.Group)
+	return err == nil && time.Now().Before(a.RetryAt)
+}
+
 func (ps passphraseScreen) checkAnswer() (tea.Model, tea.Cmd) {
-	ok, err := data.GroupIsPassphraseCorrect(ps.usr.Group, ps.passphrase.Value())
-	if err != nil || !ok {
+	if ps.waiting() {
+		return ps, nil
+	}
+	answer := ps.passphrase.Value()
+	ok, err := ps.svc.GroupIsPassphraseCorrect(ps.usr.Group, answer)
+	if err != nil {
 		log.Println(err)
+		ps.status = "couldn't check passphrase, try again"
 		return ps, nil
 	}
-	err = data.GroupEndAllGame(ps.usr.Group)
+	if !ok {
+		ps.status = fmt.Sprintf("%q is not the passphrase", answer)
+		ps.passphrase.SetValue("")
+		return ps, doTick()
+	}
+	err = ps.svc.GroupEndAllGame(ps.usr.Group)
 	if err != nil {
+		if ended, _ := ps.svc.GroupAllGameEnded(ps.usr.Group); ended {
+			// a teammate answered first
+			return ps.end()
+		}
 		// TODO handle error and show it to user
 		log.Println(err)
 		return ps, nil
 	}
 	log.Println("checkAnswer game ended")
-	return endScreen{height: ps.height, width: ps.width}.Update(nil)
+	return ps.end()
 
 }
 
 func (ps passphraseScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	ok, err := data.GroupAllGameEnded(ps.usr.Group)
+	ok, err := ps.svc.GroupAllGameEnded(ps.usr.Group)
 	if err == nil && ok {
-		return endScreen{height: ps.height, width: ps.width}.Update(nil)
+		return ps.end()
 	}
 	ps.passphrase.Focus()
 	switch msg := msg.(type) {
@@ -127,6 +181,15 @@ func (ps passphraseScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		ps = ps.doResize(msg)
 		return ps, nil
+	case tickMsg, data.GroupChangedMsg:
+		if _, ok := msg.(data.GroupChangedMsg); ok && ps.restart != nil && playing(ps.svc, ps.usr.Group) {
+			return ps.restart(ps.height, ps.width)
+		}
+		// keep the cooldown countdown moving, a teammate may have answered
+		if ps.waiting() {
+			return ps, doTick()
+		}
+		return ps, nil
 	}
 	var cmd tea.Cmd
 	ps.passphrase, cmd = ps.passphrase.Update(msg)
@@ -134,35 +197,74 @@ func (ps passphraseScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (ps passphraseScreen) View() string {
+	rows := []string{"Please enter passphrase", ps.passphrase.View()}
+	if a, err := ps.svc.GetGroupAttempts(ps.usr.Group); err != nil {
+		log.Println(err)
+	} else {
+		if ps.status != "" {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorYellow).Render(ps.status))
+		}
+		if wait := time.Until(a.RetryAt); wait > 0 {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorYellow).
+				Render(fmt.Sprintf("wrong passphrase, try again in %d seconds", int(wait.Seconds()+0.999))))
+		}
+		if a.Count != 0 {
+			rows = append(rows, lipgloss.NewStyle().Foreground(colorSecondary).
+				Render(fmt.Sprintf("%d attempts, %d wrong", a.Count, a.Wrong)))
+		}
+	}
+	panel, err := inventoryPanel(ps.svc, ps.usr.Group, ps.letterColor)
+	if err != nil {
+		log.Println(err)
+	} else if panel != "" {
+		rows = append([]string{panel, ""}, rows...)
+	}
 	return lipgloss.Place(ps.width, ps.height, lipgloss.Center, lipgloss.Center,
-		lipgloss.JoinVertical(lipgloss.Left, "Please enter passphrase", ps.passphrase.View()))
+		lipgloss.JoinVertical(lipgloss.Left, rows...))
 }
 
 type game struct {
-	err                 error
-	updateCounter       int
-	usr                 user.User
+	svc           data.GameService
+	err           error
+	updateCounter int
+	usr           user.User
+	// session is id of the program running the game
+	session             int
 	width               int
 	height              int
 	crrntRow            int
 	crrntCol            int
+	gameIndex           int
+	cursorPublished     bool
+	publishedRow        int
+	publishedCol        int
+	direction           config.Direction
 	questionBorderColor lipgloss.Color
 	questionTextColor   lipgloss.Color
 	keyColor            lipgloss.Color
 	currentKeyColor     lipgloss.Color
 	passPhraseKeyColor  lipgloss.Color
+	wordKeyColor        lipgloss.Color
+	questionSelected    lipgloss.Color
+	// status is a message for the player, cleared on next key press
+	status string
+	// hints used and left, refreshed on every update
+	hints data.Hints
+	// restart builds the game again, screens shown after the games use it
+	// to go back to the grid
+	restart func(height, width int) (tea.Model, tea.Cmd)
 }
 
 func (g *game) Init() tea.Cmd {
 	return nil
 }
 func (g *game) afterGameView() string {
-	rowCount, err := data.GetGroupRows(g.usr.Group)
+	rowCount, err := g.svc.GetGroupRows(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return "an error accured: " + err.Error() + " press any keyboard key to exit"
 	}
-	colCount, err := data.GetGroupCols(g.usr.Group)
+	colCount, err := g.svc.GetGroupCols(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return "an error accured: " + err.Error() + " press any keyboard key to exit"
@@ -171,7 +273,7 @@ func (g *game) afterGameView() string {
 	for i := 0; i < rowCount; i++ {
 		var cols []string = make([]string, colCount)
 		for j := 0; j < colCount; j++ {
-			k, err := data.GetGroupRowColumn(g.usr.Group, i, j)
+			k, err := g.svc.GetGroupRowColumn(g.usr.Group, i, j)
 			if err != nil {
 				g.err = err
 				return "an error accured: " + err.Error() + " press any keyboard key to exit"
@@ -201,7 +303,7 @@ func (g *game) View() string {
 	if g.err != nil {
 		return "an error accured: " + g.err.Error() + " press any keyboard key to exit"
 	}
-	isAfterGame, err := data.IsAfterGame(g.usr.Group)
+	isAfterGame, err := g.svc.GroupIsAfterGame(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return "an error accured: " + err.Error() + " press any keyboard key to exit"
@@ -209,12 +311,28 @@ func (g *game) View() string {
 	if isAfterGame {
 		return g.afterGameView()
 	}
-	rowCount, err := data.GetGroupRows(g.usr.Group)
+	rowCount, err := g.svc.GetGroupRows(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return "an error accured: " + err.Error() + " press any keyboard key to exit"
 	}
-	colCount, err := data.GetGroupCols(g.usr.Group)
+	colCount, err := g.svc.GetGroupCols(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return "an error accured: " + err.Error() + " press any keyboard key to exit"
+	}
+	clues, err := g.svc.GetGroupClues(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return "an error accured: " + err.Error() + " press any keyboard key to exit"
+	}
+	numbers := clueNumbers(clues)
+	word, inWord, err := g.currentWord()
+	if err != nil {
+		g.err = err
+		return "an error accured: " + err.Error() + " press any keyboard key to exit"
+	}
+	positions, others, err := g.teammates()
 	if err != nil {
 		g.err = err
 		return "an error accured: " + err.Error() + " press any keyboard key to exit"
@@ -223,22 +341,54 @@ func (g *game) View() string {
 	for i := 0; i < rowCount; i++ {
 		var cols []string = make([]string, colCount)
 		for j := 0; j < colCount; j++ {
-			k, err := data.GetGroupRowColumn(g.usr.Group, i, j)
+			k, err := g.svc.GetGroupRowColumn(g.usr.Group, i, j)
 			if err != nil {
 				g.err = err
 				return "an error accured: " + err.Error() + " press any keyboard key to exit"
 			}
 
 			if i == g.crrntRow && j == g.crrntCol {
-				cols[j] = k.Render(g.currentKeyColor)
+				cols[j] = k.RenderNumbered(g.currentKeyColor, numbers[[2]int{i, j}])
+			} else if c, ok := positions[[2]int{i, j}]; ok {
+				cols[j] = k.RenderNumbered(c.color, numbers[[2]int{i, j}])
+			} else if inWord && word.Contains(i, j) {
+				cols[j] = k.RenderNumbered(g.wordKeyColor, numbers[[2]int{i, j}])
 			} else {
-				cols[j] = k.Render(g.keyColor)
+				cols[j] = k.RenderNumbered(g.keyColor, numbers[[2]int{i, j}])
 			}
 		}
 		rows[i] = lipgloss.JoinHorizontal(lipgloss.Bottom, cols...)
 	}
-	questionList, err := data.GetGroupQuestions(g.usr.Group)
+	questionList, err := g.svc.GetGroupQuestions(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return "an error accured: " + err.Error() + " press any keyboard key to exit"
+	}
+	panel, err := inventoryPanel(g.svc, g.usr.Group, g.passPhraseKeyColor)
+	if err != nil {
+		g.err = err
+		return "an error accured: " + err.Error() + " press any keyboard key to exit"
+	}
+	if panel != "" {
+		rows = append(rows, panel)
+	}
+	if len(others) != 0 {
+		rows = append(rows, legend(others))
+	}
+	rows = append(rows, lipgloss.NewStyle().Foreground(colorSecondary).
+		Render("tab direction • ctrl+w clear word • ctrl+l leaderboard"))
+	if g.hints.Limit != 0 {
+		rows = append(rows, lipgloss.NewStyle().Foreground(colorSecondary).
+			Render(fmt.Sprintf("ctrl+r hint letter • ctrl+e hint word, a hint per letter • %d hints left",
+				g.hints.Left())))
+	}
+	if g.status != "" {
+		rows = append(rows, lipgloss.NewStyle().Foreground(colorYellow).Render(g.status))
+	}
 	table := lipgloss.JoinVertical(lipgloss.Center, rows...)
+	if len(clues) != 0 {
+		questionList = clueList(clues, word, lipgloss.NewStyle().Bold(true).Foreground(g.questionSelected))
+	}
 	questions := lipgloss.JoinVertical(lipgloss.Left, questionList...)
 	questions = lipgloss.NewStyle().
 		Padding(0, 1).
@@ -251,12 +401,48 @@ func (g *game) View() string {
 }
 
 func (g *game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := g.update(msg)
+	if m != tea.Model(g) || g.err != nil {
+		return m, cmd
+	}
+	// read here rather than in View, which runs on every render
+	hints, err := g.svc.GetGroupHints(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return m, func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+	g.hints = hints
+	return m, tea.Batch(cmd, g.publishCursor())
+}
+
+func (g *game) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if g.err != nil {
 		return g, tea.Quit
 	}
+	// ended by the contest deadline, a teammate answering the passphrase
+	// or an admin
+	ended, err := g.svc.GroupAllGameEnded(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return g, tea.Quit
+	}
+	if ended || g.svc.ContestEnded() {
+		return endScreen{svc: g.svc, contest: g.usr.Group.Contest, grp: g.usr.Group, restart: g.restart,
+			height: g.height, width: g.width}.Update(nil)
+	}
+	if _, ok := msg.(data.GroupChangedMsg); ok {
+		return g, g.sync()
+	}
+	if _, ok := msg.(tickMsg); ok {
+		// left over from leaderboard
+		return g, nil
+	}
 	if _, ok := msg.(AllDoneMsg); ok {
 		mdl := textinput.New()
-		return passphraseScreen{height: g.height, width: g.width, usr: g.usr, passphrase: mdl}.Update(nil)
+		return passphraseScreen{svc: g.svc, height: g.height, width: g.width, usr: g.usr, passphrase: mdl,
+			letterColor: g.passPhraseKeyColor, restart: g.restart}.Update(nil)
 	}
 	if g.Ended() {
 		if g.updateCounter < 1 {
@@ -271,6 +457,7 @@ func (g *game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		//TODO show appropriate view end screen
 		return g, g.gotoNextGame()
 	case tea.KeyMsg:
+		g.status = ""
 		switch msg.Type {
 		case tea.KeyRight:
 			return g, g.goRight()
@@ -280,6 +467,20 @@ func (g *game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return g, g.goUp()
 		case tea.KeyDown:
 			return g, g.goDown()
+		case tea.KeyTab, tea.KeySpace:
+			return g, g.toggleDirection()
+		case tea.KeyBackspace, tea.KeyCtrlH:
+			return g, g.backspace()
+		case tea.KeyDelete:
+			return g, g.clearKey(g.crrntRow, g.crrntCol)
+		case tea.KeyCtrlW:
+			return g, g.clearWord()
+		case tea.KeyCtrlR:
+			return g, g.hint(false)
+		case tea.KeyCtrlE:
+			return g, g.hint(true)
+		case tea.KeyCtrlL:
+			return leaderboard{svc: g.svc, height: g.height, width: g.width, usr: g.usr, back: g}.Update(nil)
 		case tea.KeyCtrlC:
 			return g, tea.Quit
 		case tea.KeyRunes:
@@ -295,8 +496,41 @@ func (g *game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 type AllDoneMsg struct{}
 
+// clueNumbers maps starting cell of each clue to its number
+func clueNumbers(clues []config.Clue) map[[2]int]int {
+	numbers := make(map[[2]int]int)
+	for _, c := range clues {
+		numbers[[2]int{c.Row, c.Col}] = c.Number
+	}
+	return numbers
+}
+
+// clueList splits clues into Across and Down lists, each sorted by number.
+// active clue is rendered with selected style
+func clueList(clues []config.Clue, active config.Clue, selected lipgloss.Style) []string {
+	sorted := make([]config.Clue, len(clues))
+	copy(sorted, clues)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Number < sorted[j].Number
+	})
+	across := []string{"Across"}
+	down := []string{"", "Down"}
+	for _, c := range sorted {
+		line := c.String()
+		if c == active {
+			line = selected.Render(line)
+		}
+		if c.Direction == config.Down {
+			down = append(down, line)
+		} else {
+			across = append(across, line)
+		}
+	}
+	return append(across, down...)
+}
+
 func (g *game) gotoNextGame() tea.Cmd {
-	err := data.GroupGotoNextGame(g.usr.Group)
+	err := g.svc.GroupGotoNextGame(g.usr.Group)
 	if err != nil {
 		if _, ok := err.(data.AllGamesDoneError); ok {
 			return func() tea.Msg {
@@ -311,18 +545,72 @@ func (g *game) gotoNextGame() tea.Cmd {
 
 	var initialRow, initialCol int
 
-	initialCol, err = data.GetGroupInitialCol(g.usr.Group)
+	initialCol, err = g.svc.GetGroupInitialCol(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return nil
+	}
+
+	initialRow, err = g.svc.GetGroupInitialRow(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return nil
+	}
+
+	g.gameIndex, err = g.svc.GetGroupCurrentGameIndex(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return nil
+	}
+	g.cursorPublished = false
+
+	g.crrntCol = initialCol
+	g.crrntRow = initialRow
+	return nil
+}
+
+// sync catches up with changes made by teammates, cursor goes back to the
+// initial key when they moved to the next game
+func (g *game) sync() tea.Cmd {
+	index, err := g.svc.GetGroupCurrentGameIndex(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+	if index == g.gameIndex {
+		// a reset drops cursors of the group without changing the game
+		cursors, err := g.svc.GetGroupCursors(g.usr.Group)
+		if err != nil {
+			g.err = err
+			return func() tea.Msg {
+				return errAccuredMsg{}
+			}
+		}
+		published := false
+		for _, c := range cursors {
+			published = published || c.Session == g.session
+		}
+		g.cursorPublished = g.cursorPublished && published
+		return nil
+	}
+
+	initialCol, err := g.svc.GetGroupInitialCol(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return nil
 	}
 
-	initialRow, err = data.GetGroupInitialRow(g.usr.Group)
+	initialRow, err := g.svc.GetGroupInitialRow(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return nil
 	}
 
+	g.gameIndex = index
+	g.updateCounter = 0
+	g.cursorPublished = false
 	g.crrntCol = initialCol
 	g.crrntRow = initialRow
 	return nil
@@ -331,7 +619,7 @@ func (g *game) gotoNextGame() tea.Cmd {
 type errAccuredMsg struct{}
 
 func (g *game) goDown() tea.Cmd {
-	rowCount, err := data.GetGroupRows(g.usr.Group)
+	rowCount, err := g.svc.GetGroupRows(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return func() tea.Msg {
@@ -341,7 +629,7 @@ func (g *game) goDown() tea.Cmd {
 	if g.crrntRow+1 == rowCount {
 		return nil
 	}
-	k, err := data.GetGroupRowColumn(g.usr.Group, g.crrntRow+1, g.crrntCol)
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, g.crrntRow+1, g.crrntCol)
 	if err != nil {
 		g.err = err
 		return func() tea.Msg {
@@ -360,7 +648,7 @@ func (g *game) goUp() tea.Cmd {
 	if g.crrntRow == 0 {
 		return nil
 	}
-	k, err := data.GetGroupRowColumn(g.usr.Group, g.crrntRow-1, g.crrntCol)
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, g.crrntRow-1, g.crrntCol)
 	if err != nil {
 		g.err = err
 		return func() tea.Msg {
@@ -379,7 +667,7 @@ func (g *game) goLeft() tea.Cmd {
 	if g.crrntCol == 0 {
 		return nil
 	}
-	k, err := data.GetGroupRowColumn(g.usr.Group, g.crrntRow, g.crrntCol-1)
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, g.crrntRow, g.crrntCol-1)
 	if err != nil {
 		g.err = err
 		return func() tea.Msg {
@@ -395,7 +683,7 @@ func (g *game) goLeft() tea.Cmd {
 }
 
 func (g *game) goRight() tea.Cmd {
-	colCount, err := data.GetGroupCols(g.usr.Group)
+	colCount, err := g.svc.GetGroupCols(g.usr.Group)
 	if err != nil {
 		g.err = err
 		return func() tea.Msg {
@@ -405,7 +693,7 @@ func (g *game) goRight() tea.Cmd {
 	if g.crrntCol+1 == colCount {
 		return nil
 	}
-	k, err := data.GetGroupRowColumn(g.usr.Group, g.crrntRow, g.crrntCol+1)
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, g.crrntRow, g.crrntCol+1)
 	if err != nil {
 		g.err = err
 		return func() tea.Msg {
@@ -421,7 +709,7 @@ func (g *game) goRight() tea.Cmd {
 }
 
 func (g *game) insertKey(r rune) tea.Cmd {
-	k, err := data.GetGroupRowColumn(g.usr.Group, g.crrntRow, g.crrntCol)
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, g.crrntRow, g.crrntCol)
 	if err != nil {
 		g.err = err
 		return func() tea.Msg {
@@ -429,14 +717,14 @@ func (g *game) insertKey(r rune) tea.Cmd {
 		}
 	}
 
-	if !unicode.IsLetter(r) || k.State == key.READONLY {
+	// digits are answers too, everything else that has no key is ignored
+	char, ok := key.Letters[unicode.ToUpper(r)]
+	if !ok || char == key.EMPTY || k.State == key.READONLY {
 		return nil
 	}
 
-	r = unicode.ToUpper(r)
-	char := key.Letters[r]
 	k.Char = char
-	err = data.GroupInsertKeyAt(g.usr.Group, k, g.crrntRow, g.crrntCol)
+	err = g.svc.GroupInsertKeyAt(g.usr.Group, k, g.crrntRow, g.crrntCol)
 
 	if err != nil {
 		g.err = err
@@ -449,15 +737,152 @@ func (g *game) insertKey(r rune) tea.Cmd {
 		g.updateCounter = 0
 		return g.EndGame()
 	}
+
+	if err = g.advance(); err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
 	return nil
 }
+
+// clearKey empties the key at row, col for the whole group
+func (g *game) clearKey(row, col int) tea.Cmd {
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, row, col)
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+
+	if k.State == key.READONLY || isBlank(k) {
+		return nil
+	}
+
+	k.Char = key.EMPTY
+	err = g.svc.GroupInsertKeyAt(g.usr.Group, k, row, col)
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+	return nil
+}
+
+// backspace clears the current key and moves back, if current key is
+// already blank the previous one is cleared instead
+func (g *game) backspace() tea.Cmd {
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, g.crrntRow, g.crrntCol)
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+
+	if !isBlank(k) {
+		cmd := g.clearKey(g.crrntRow, g.crrntCol)
+		if g.err != nil {
+			return cmd
+		}
+	}
+
+	if err = g.retreat(); err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+
+	if isBlank(k) {
+		return g.clearKey(g.crrntRow, g.crrntCol)
+	}
+	return nil
+}
+
+// clearWord empties every key of the current word
+func (g *game) clearWord() tea.Cmd {
+	w, ok, err := g.currentWord()
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	for i := 0; i < w.Length; i++ {
+		row, col := w.Cell(i)
+		if cmd := g.clearKey(row, col); g.err != nil {
+			return cmd
+		}
+	}
+	g.crrntRow, g.crrntCol = w.Row, w.Col
+	return nil
+}
+
+// hint reveals the letter under the cursor, or every letter of the current
+// word if word is true, using one of the group's hints
+func (g *game) hint(word bool) tea.Cmd {
+	h, err := g.svc.GetGroupHints(g.usr.Group)
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+	if h.Limit == 0 {
+		g.status = "hints are disabled"
+		return nil
+	}
+	if h.Left() == 0 {
+		g.status = "no hints left"
+		return nil
+	}
+	cells := [][2]int{{g.crrntRow, g.crrntCol}}
+	if word {
+		w, ok, err := g.currentWord()
+		if err != nil {
+			g.err = err
+			return func() tea.Msg {
+				return errAccuredMsg{}
+			}
+		}
+		if ok {
+			cells = cells[:0]
+			for i := 0; i < w.Length; i++ {
+				row, col := w.Cell(i)
+				cells = append(cells, [2]int{row, col})
+			}
+		}
+	}
+	if err = g.svc.GroupHint(g.usr.Group, cells); err == data.ErrNothingToReveal {
+		g.status = "nothing to reveal"
+		return nil
+	} else if err != nil {
+		log.Println(err)
+		g.status = "couldn't use a hint"
+		return nil
+	}
+	if g.Ended() {
+		g.updateCounter = 0
+		return g.EndGame()
+	}
+	return nil
+}
+
 func (g *game) doResize(msg tea.WindowSizeMsg) tea.Cmd {
 	g.height = msg.Height
 	g.width = msg.Width
 	return nil
 }
 func (g *game) Ended() bool {
-	ended, err := data.GroupGameEnded(g.usr.Group)
+	ended, err := g.svc.GroupGameEnded(g.usr.Group)
 
 	if err != nil {
 		g.err = err
@@ -473,19 +898,25 @@ func (g *game) EndGame() tea.Cmd {
 
 }
 
-func newGame(cfg config.Colors, height, width int, u user.User) (_ *game, err error) {
-	var initialRow, initialCol int
+func newGame(svc data.GameService, cfg config.Colors, height, width int, u user.User, prog *program) (_ *game, err error) {
+	var initialRow, initialCol, gameIndex int
 
-	initialCol, err = data.GetGroupInitialCol(u.Group)
+	initialCol, err = svc.GetGroupInitialCol(u.Group)
 	if err != nil {
 		return
 	}
 
-	initialRow, err = data.GetGroupInitialRow(u.Group)
+	initialRow, err = svc.GetGroupInitialRow(u.Group)
+	if err != nil {
+		return
+	}
+
+	gameIndex, err = svc.GetGroupCurrentGameIndex(u.Group)
 	if err != nil {
 		return
 	}
 	g := game{}
+	g.svc = svc
 	g.height = height
 	g.width = width
 	g.questionBorderColor = cfg.QuestionBorderColor
@@ -493,13 +924,26 @@ func newGame(cfg config.Colors, height, width int, u user.User) (_ *game, err er
 	g.currentKeyColor = cfg.TableSelectedKeyColor
 	g.keyColor = cfg.TableEditableKeyColor
 	g.passPhraseKeyColor = cfg.PassPhraseKeyColor
+	g.wordKeyColor = cfg.TableWordKeyColor
+	if g.wordKeyColor == "" {
+		g.wordKeyColor = colorYellow
+	}
+	g.questionSelected = cfg.QuestionSelectedColor
+	if g.questionSelected == "" {
+		g.questionSelected = colorGreen
+	}
+	g.direction = config.Across
 	g.usr = u
+	g.gameIndex = gameIndex
 	g.crrntCol = initialCol
 	g.crrntRow = initialRow
+	g.session = prog.id
+	prog.login(u)
+	svc.GroupSubscribe(u.Group, prog)
 	return &g, nil
 }
 
 // TODO show time of other users realtime
 // TODO cause everybody can register multiple times with different names and cheat \
-// it would be nice if 1. start game at fixed time or 2. have multiple with same level of difficulity
+// it would be nice to have multiple with same level of difficulity
 // or limit registration, so only verified users will be able to play
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/amirkhaki/crossword/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEraseKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		row     string
+		from    cell
+		key     tea.KeyType
+		wantRow string
+		want    cell
+	}{
+		{name: "backspace clears and moves back", row: "CAT", from: cell{0, 2, config.Across}, key: tea.KeyBackspace,
+			wantRow: "CA ", want: cell{0, 1, config.Across}},
+		{name: "backspace on a blank key clears the previous one", row: "CA", from: cell{0, 2, config.Across}, key: tea.KeyBackspace,
+			wantRow: "C  ", want: cell{0, 1, config.Across}},
+		{name: "backspace at the start of a word", row: "CAT", from: cell{0, 0, config.Across}, key: tea.KeyBackspace,
+			wantRow: " AT", want: cell{0, 0, config.Across}},
+		{name: "backspace on an empty word", from: cell{0, 0, config.Across}, key: tea.KeyBackspace,
+			wantRow: "   ", want: cell{0, 0, config.Across}},
+		{name: "ctrl+h is backspace", row: "CAT", from: cell{0, 2, config.Across}, key: tea.KeyCtrlH,
+			wantRow: "CA ", want: cell{0, 1, config.Across}},
+		{name: "delete clears in place", row: "CAT", from: cell{0, 1, config.Across}, key: tea.KeyDelete,
+			wantRow: "C T", want: cell{0, 1, config.Across}},
+		{name: "clear word", row: "CAT", from: cell{0, 2, config.Across}, key: tea.KeyCtrlW,
+			wantRow: "   ", want: cell{0, 0, config.Across}},
+		{name: "clear word down keeps the rest of the row", row: "CAT", from: cell{1, 0, config.Down}, key: tea.KeyCtrlW,
+			wantRow: " AT", want: cell{0, 0, config.Down}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestData(t)
+			fillRow(t, d, 0, tt.row)
+			g := newTestGame(t, d, "alice")
+			g.crrntRow, g.crrntCol, g.direction = tt.from.row, tt.from.col, tt.from.dir
+
+			g.Update(tea.KeyMsg{Type: tt.key})
+			if g.err != nil {
+				t.Fatal(g.err)
+			}
+			// erasing goes through data, so teammates see it too
+			if got := rowOf(t, d, 0); got != tt.wantRow {
+				t.Errorf("row = %q, want %q", got, tt.wantRow)
+			}
+			if got := (cell{g.crrntRow, g.crrntCol, g.direction}); got != tt.want {
+				t.Errorf("cursor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       rune
+		wantRow string
+		want    cell
+	}{
+		{name: "letter", r: 'c', wantRow: "C  ", want: cell{0, 1, config.Across}},
+		{name: "digit", r: '7', wantRow: "7  ", want: cell{0, 1, config.Across}},
+		{name: "punctuation is ignored", r: '!', wantRow: "   ", want: cell{0, 0, config.Across}},
+		{name: "letters without a key are ignored", r: 'é', wantRow: "   ", want: cell{0, 0, config.Across}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestData(t)
+			g := newTestGame(t, d, "alice")
+
+			g.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{tt.r}})
+			if g.err != nil {
+				t.Fatal(g.err)
+			}
+			if got := rowOf(t, d, 0); got != tt.wantRow {
+				t.Errorf("row = %q, want %q", got, tt.wantRow)
+			}
+			if got := (cell{g.crrntRow, g.crrntCol, g.direction}); got != tt.want {
+				t.Errorf("cursor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
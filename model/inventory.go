@@ -0,0 +1,23 @@
+package model
+
+import (
+	"github.com/amirkhaki/crossword/data"
+	"github.com/amirkhaki/crossword/user"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inventoryPanel renders passphrase letters grp collected so far, it is
+// empty if games have no passphrase cells
+func inventoryPanel(svc data.GameService, grp user.Group, color lipgloss.Color) (string, error) {
+	inv, err := svc.GetGroupInventory(grp)
+	if err != nil || len(inv.Letters) == 0 {
+		return "", err
+	}
+	letters := lipgloss.NewStyle().Bold(true).Foreground(color).Render(inv.String())
+	return lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(color).
+		Render(lipgloss.JoinVertical(lipgloss.Left, "Passphrase letters", letters)), nil
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/amirkhaki/crossword/data"
+	"github.com/amirkhaki/crossword/user"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// leaderboard ranks every group while the game goes on, back is the model
+// to return to
+type leaderboard struct {
+	svc    data.GameService
+	height int
+	width  int
+	usr    user.User
+	back   tea.Model
+	inited bool
+}
+
+func (l leaderboard) Init() tea.Cmd {
+	return nil
+}
+
+func (l leaderboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return l, tea.Quit
+		case "esc", "ctrl+l":
+			return l.back, nil
+		}
+	case tea.WindowSizeMsg:
+		l.height = msg.Height
+		l.width = msg.Width
+		l.back, cmd = l.back.Update(msg)
+		return l, cmd
+	case data.GroupChangedMsg:
+		// keep the game in sync, leave if it moved on to another screen
+		back, cmd := l.back.Update(msg)
+		if back != l.back {
+			return back, cmd
+		}
+		return l, cmd
+	case tickMsg:
+		cmd = doTick()
+	}
+	if !l.inited {
+		l.inited = true
+		cmd = doTick()
+	}
+	return l, cmd
+}
+
+func (l leaderboard) View() string {
+	style := lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62"))
+	rows := []string{lipgloss.NewStyle().Bold(true).Render("Leaderboard"), ""}
+	for i, v := range l.svc.GetLeaderboard(l.usr.Group.Contest) {
+		row := fmt.Sprintf("%2d. %s\n    %s", i+1, v.Title(), v.Desciption())
+		if v.Title() == l.usr.Group.Name {
+			row = lipgloss.NewStyle().Foreground(colorGreen).Render(row)
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, "", lipgloss.NewStyle().Foreground(colorSecondary).Render("esc to go back to game"))
+	return lipgloss.Place(l.width, l.height, lipgloss.Center, lipgloss.Center,
+		style.Render(lipgloss.JoinVertical(lipgloss.Left, rows...)))
+}
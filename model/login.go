@@ -2,8 +2,10 @@ package model
 
 import (
 	"context"
+	"log"
 
 	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/data"
 	"github.com/amirkhaki/crossword/storage"
 	"github.com/amirkhaki/crossword/user"
 
@@ -13,7 +15,10 @@ import (
 )
 
 type login struct {
+	svc      data.GameService
+	store    storage.Storage
 	cfg      config.Config
+	program  *program
 	status   string
 	height   int
 	width    int
@@ -28,16 +33,10 @@ func (l login) Init() tea.Cmd {
 func (l login) loginUser() (tea.Model, tea.Cmd) {
 	username := l.username.Value()
 	password := l.password.Value()
-	u, err := storage.Store.GetUser(context.Background(),
-		user.NewUser(username, password, user.Group{}), func(u1, u2 user.User) bool {
-			if u1.Username == u2.Username && u1.Password == u2.Password {
-				return true
-			}
-			return false
-		})
+	u, err := storage.Authenticate(context.Background(), l.store, username, password)
 	if err != nil {
 		_, ok := err.(storage.UserNotFoundError)
-		form := NewLogin(l.cfg, l.height, l.width)
+		form := l.reset()
 		if ok {
 			form.status = "invalid username and/or password! try again"
 		} else {
@@ -45,13 +44,56 @@ func (l login) loginUser() (tea.Model, tea.Cmd) {
 		}
 		return form, nil
 	}
-	g, err := newGame(l.cfg.Colors, l.height, l.width, u)
-	if err != nil {
-		form := NewLogin(l.cfg, l.height, l.width)
-		form.status = "an error accured: " + err.Error()
-		return form, nil
+	return l.LoginAs(u)
+}
+
+// LoginAs skips the login form and starts the game for u
+func (l login) LoginAs(u user.User) (tea.Model, tea.Cmd) {
+	if u.Admin {
+		return newAdmin(l.svc, l.height, l.width, u).Update(nil)
+	}
+	var start func(height, width int) (tea.Model, tea.Cmd)
+	start = func(height, width int) (tea.Model, tea.Cmd) {
+		g, err := newGame(l.svc, l.cfg.Colors, height, width, u, l.program)
+		if err != nil {
+			form := l.reset()
+			form.height, form.width = height, width
+			form.status = "an error accured: " + err.Error()
+			return form, nil
+		}
+		g.restart = start
+		return g.Update(nil)
+	}
+	if !l.svc.ContestStarted() {
+		return countdown{svc: l.svc, height: l.height, width: l.width, next: start}.Update(nil)
+	}
+	return start(l.height, l.width)
+}
+
+// reset returns an empty login form attached to the same program
+func (l login) reset() login {
+	form := NewLogin(l.cfg, l.svc, l.store, l.height, l.width)
+	form.program = l.program
+	return form
+}
+
+// Attach binds p as the program running this session, so that changes made
+// by teammates are sent to it
+func (l login) Attach(p *tea.Program) {
+	go l.program.forward(p)
+}
+
+// Detach stops sending changes to the attached program, it must be called
+// once, as soon as the program exits
+func (l login) Detach() {
+	l.svc.Unsubscribe(l.program)
+	close(l.program.done)
+	if u, ok := l.program.loggedInUser(); ok {
+		err := l.svc.GroupRemoveCursor(u.Group, l.program.id)
+		if err != nil {
+			log.Println(err)
+		}
 	}
-	return g.Update(nil)
 }
 
 func (l login) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -96,13 +138,14 @@ func (l login) View() string {
 		lipgloss.JoinVertical(lipgloss.Left, status, l.username.View(), l.password.View()))
 }
 
-func NewLogin(cfg config.Config, height, width int) login {
-	l := login{height: height, width: width}
+func NewLogin(cfg config.Config, svc data.GameService, store storage.Storage, height, width int) login {
+	l := login{svc: svc, store: store, height: height, width: width}
 	l.username = textinput.New()
 	l.username.Placeholder = "username"
 	l.username.Focus()
 	l.password = textinput.New()
 	l.password.Placeholder = "password"
 	l.cfg = cfg
+	l.program = newProgram()
 	return l
 }
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/amirkhaki/crossword/user"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// program forwards changes of a group to the tea.Program running a session.
+// models are created before their program, which is handed over later by
+// login.Attach. usr is set by the program once the session logs in and read
+// when the session ends, so it is guarded by mu
+type program struct {
+	// id tells sessions of the same user apart
+	id       int
+	mu       sync.Mutex
+	usr      user.User
+	loggedIn bool
+	// msgs keeps the message waiting to be sent to the program, one is enough because
+	// models reload state of their group on any change
+	msgs chan tea.Msg
+	// done is closed when the session ends
+	done chan struct{}
+}
+
+// programs is number of programs created so far, used to give them ids
+var programs int64
+
+func newProgram() *program {
+	id := int(atomic.AddInt64(&programs, 1))
+	return &program{id: id, msgs: make(chan tea.Msg, 1), done: make(chan struct{})}
+}
+
+// login records that the session logged in as u
+func (p *program) login(u user.User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.usr = u
+	p.loggedIn = true
+}
+
+// loggedInUser returns the user the session logged in as, if any
+func (p *program) loggedInUser() (user.User, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usr, p.loggedIn
+}
+
+// Send queues msg for the program and never blocks, messages sent while
+// another one is waiting are dropped
+func (p *program) Send(msg tea.Msg) {
+	select {
+	case p.msgs <- msg:
+	default:
+	}
+}
+
+// forward sends queued messages to tp until the session ends. tp.Send
+// returns without sending once tp has exited, so a change published after
+// the program exits but before Detach doesn't block forward
+func (p *program) forward(tp *tea.Program) {
+	for {
+		select {
+		case <-p.done:
+			return
+		case msg := <-p.msgs:
+			tp.Send(msg)
+		}
+	}
+}
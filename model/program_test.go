@@ -0,0 +1,40 @@
+package model
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quitting is a model whose program exits right away
+type quitting struct{}
+
+func (quitting) Init() tea.Cmd                       { return tea.Quit }
+func (quitting) Update(tea.Msg) (tea.Model, tea.Cmd) { return quitting{}, nil }
+func (quitting) View() string                        { return "" }
+
+func TestForwardAfterProgramExits(t *testing.T) {
+	tp := tea.NewProgram(quitting{}, tea.WithInput(nil), tea.WithOutput(io.Discard))
+	if _, err := tp.Run(); err != nil {
+		t.Fatal(err)
+	}
+	p := newProgram()
+	stopped := make(chan struct{})
+	go func() {
+		p.forward(tp)
+		close(stopped)
+	}()
+	// forward takes the first message and tries to send it to the exited
+	// program, the second one fills the queue again
+	p.Send(struct{}{})
+	p.Send(struct{}{})
+	time.Sleep(10 * time.Millisecond)
+	close(p.done)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("forward is blocked sending to an exited program")
+	}
+}
@@ -0,0 +1,228 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/key"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func otherDirection(dir config.Direction) config.Direction {
+	if dir == config.Down {
+		return config.Across
+	}
+	return config.Down
+}
+
+// wordAt returns the word containing row, col in direction dir. words are
+// taken from clues, puzzles without clues fall back to runs of non readonly keys
+func (g *game) wordAt(dir config.Direction, row, col int) (w config.Clue, ok bool, err error) {
+	clues, err := g.svc.GetGroupClues(g.usr.Group)
+	if err != nil {
+		return
+	}
+	if len(clues) != 0 {
+		for _, c := range clues {
+			if c.Direction == dir && c.Contains(row, col) {
+				return c, true, nil
+			}
+		}
+		return
+	}
+
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, row, col)
+	if err != nil || k.State == key.READONLY {
+		return
+	}
+	w = config.Clue{Direction: dir, Row: row, Col: col}
+	for {
+		r, c := w.Cell(-1)
+		editable, err := g.isEditable(r, c)
+		if err != nil {
+			return w, false, err
+		}
+		if !editable {
+			break
+		}
+		w.Row, w.Col = r, c
+	}
+	for {
+		r, c := w.Cell(w.Length)
+		editable, err := g.isEditable(r, c)
+		if err != nil {
+			return w, false, err
+		}
+		if !editable {
+			break
+		}
+		w.Length++
+	}
+	return w, true, nil
+}
+
+// isEditable reports whether row, col is inside the grid and not readonly
+func (g *game) isEditable(row, col int) (bool, error) {
+	rowCount, err := g.svc.GetGroupRows(g.usr.Group)
+	if err != nil {
+		return false, err
+	}
+	colCount, err := g.svc.GetGroupCols(g.usr.Group)
+	if err != nil {
+		return false, err
+	}
+	if row < 0 || col < 0 || row >= rowCount || col >= colCount {
+		return false, nil
+	}
+	k, err := g.svc.GetGroupRowColumn(g.usr.Group, row, col)
+	if err != nil {
+		return false, err
+	}
+	return k.State != key.READONLY, nil
+}
+
+// currentWord returns the word under the cursor in the active direction
+func (g *game) currentWord() (config.Clue, bool, error) {
+	return g.wordAt(g.direction, g.crrntRow, g.crrntCol)
+}
+
+// toggleDirection switches between across and down, unless the cursor is
+// not on a word in the other direction
+func (g *game) toggleDirection() tea.Cmd {
+	_, ok, err := g.wordAt(otherDirection(g.direction), g.crrntRow, g.crrntCol)
+	if err != nil {
+		g.err = err
+		return func() tea.Msg {
+			return errAccuredMsg{}
+		}
+	}
+	if ok {
+		g.direction = otherDirection(g.direction)
+	}
+	return nil
+}
+
+// words returns every word in direction dir ordered by clue number, or by
+// position for puzzles without clues
+func (g *game) words(dir config.Direction) (words []config.Clue, err error) {
+	clues, err := g.svc.GetGroupClues(g.usr.Group)
+	if err != nil {
+		return
+	}
+	if len(clues) != 0 {
+		for _, c := range clues {
+			if c.Direction == dir {
+				words = append(words, c)
+			}
+		}
+		sort.SliceStable(words, func(i, j int) bool {
+			return words[i].Number < words[j].Number
+		})
+		return
+	}
+
+	rowCount, err := g.svc.GetGroupRows(g.usr.Group)
+	if err != nil {
+		return
+	}
+	colCount, err := g.svc.GetGroupCols(g.usr.Group)
+	if err != nil {
+		return
+	}
+	for i := 0; i < rowCount; i++ {
+		for j := 0; j < colCount; j++ {
+			w, ok, err := g.wordAt(dir, i, j)
+			if err != nil {
+				return nil, err
+			}
+			if ok && w.Row == i && w.Col == j && w.Length > 1 {
+				words = append(words, w)
+			}
+		}
+	}
+	return
+}
+
+func isBlank(k key.Key) bool {
+	return k.Char == key.EMPTY || k.Char == 0
+}
+
+// firstBlank returns index of the first blank key of w
+func (g *game) firstBlank(w config.Clue) (int, bool, error) {
+	for i := 0; i < w.Length; i++ {
+		row, col := w.Cell(i)
+		k, err := g.svc.GetGroupRowColumn(g.usr.Group, row, col)
+		if err != nil {
+			return 0, false, err
+		}
+		if isBlank(k) {
+			return i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// advance moves the cursor to the next editable key of the current word,
+// or to the first blank key of the next unfinished word at the end of it
+func (g *game) advance() error {
+	w, ok, err := g.currentWord()
+	if err != nil {
+		return err
+	}
+	if ok {
+		for i := 0; i < w.Length; i++ {
+			row, col := w.Cell(i)
+			if row == g.crrntRow && col == g.crrntCol && i+1 < w.Length {
+				g.crrntRow, g.crrntCol = w.Cell(i + 1)
+				return nil
+			}
+		}
+	}
+
+	current, err := g.words(g.direction)
+	if err != nil {
+		return err
+	}
+	other, err := g.words(otherDirection(g.direction))
+	if err != nil {
+		return err
+	}
+	all := append(current, other...)
+	start := 0
+	for i, c := range all {
+		if ok && c == w {
+			start = i + 1
+			break
+		}
+	}
+	for i := 0; i < len(all); i++ {
+		next := all[(start+i)%len(all)]
+		index, found, err := g.firstBlank(next)
+		if err != nil {
+			return err
+		}
+		if found {
+			g.direction = next.Direction
+			g.crrntRow, g.crrntCol = next.Cell(index)
+			return nil
+		}
+	}
+	return nil
+}
+
+// retreat moves the cursor to the previous key of the current word
+func (g *game) retreat() error {
+	w, ok, err := g.currentWord()
+	if err != nil || !ok {
+		return err
+	}
+	for i := 1; i < w.Length; i++ {
+		row, col := w.Cell(i)
+		if row == g.crrntRow && col == g.crrntCol {
+			g.crrntRow, g.crrntCol = w.Cell(i - 1)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/amirkhaki/crossword/config"
+	"github.com/amirkhaki/crossword/data"
+	"github.com/amirkhaki/crossword/key"
+)
+
+// fillRow types letters of word into row of group red, starting at the
+// first column and skipping spaces and #
+func fillRow(t *testing.T, d *data.Data, row int, word string) {
+	t.Helper()
+	for col, r := range word {
+		if r == ' ' || r == '#' {
+			continue
+		}
+		k, err := d.GetGroupRowColumn(red, row, col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k.Char = key.Letters[unicode.ToUpper(r)]
+		if err = d.GroupInsertKeyAt(red, k, row, col); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// rowOf returns letters of row of group red, a space for blank cells and #
+// for readonly ones
+func rowOf(t *testing.T, d *data.Data, row int) string {
+	t.Helper()
+	cols, err := d.GetGroupCols(red)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l []rune
+	for col := 0; col < cols; col++ {
+		k, err := d.GetGroupRowColumn(red, row, col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case k.State == key.READONLY:
+			l = append(l, '#')
+		case isBlank(k):
+			l = append(l, ' ')
+		default:
+			l = append(l, rune(k.Char))
+		}
+	}
+	return string(l)
+}
+
+// cell is a cursor position and direction
+type cell struct {
+	row, col int
+	dir      config.Direction
+}
+
+func TestAdvance(t *testing.T) {
+	tests := []struct {
+		name string
+		// rows are typed into the cat grid before the cursor moves
+		rows [3]string
+		from cell
+		want cell
+	}{
+		{name: "next key of the word", from: cell{0, 0, config.Across}, want: cell{0, 1, config.Across}},
+		{name: "next key down", from: cell{0, 2, config.Down}, want: cell{1, 2, config.Down}},
+		{name: "next word at the end of a word", from: cell{0, 2, config.Across}, want: cell{2, 0, config.Across}},
+		{name: "words of the other direction after the last word", from: cell{2, 2, config.Across}, want: cell{0, 0, config.Down}},
+		{name: "first blank key of the next word", rows: [3]string{"CAT"}, from: cell{2, 2, config.Across}, want: cell{1, 0, config.Down}},
+		{name: "finished words are skipped", rows: [3]string{"CAT", "", "BEE"}, from: cell{0, 2, config.Across}, want: cell{1, 0, config.Down}},
+		{name: "words of the other direction after the last word down", rows: [3]string{"C", "A#O", "BEE"}, from: cell{2, 2, config.Down}, want: cell{0, 1, config.Across}},
+		{name: "wraps around to the first word", rows: [3]string{"C T", "A#O", "BEE"}, from: cell{2, 2, config.Across}, want: cell{0, 1, config.Across}},
+		{name: "stays when every word is finished", rows: [3]string{"CAT", "A#O", "BEE"}, from: cell{2, 2, config.Across}, want: cell{2, 2, config.Across}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestData(t)
+			for row, word := range tt.rows {
+				fillRow(t, d, row, word)
+			}
+			g := newTestGame(t, d, "alice")
+			g.crrntRow, g.crrntCol, g.direction = tt.from.row, tt.from.col, tt.from.dir
+
+			if err := g.advance(); err != nil {
+				t.Fatal(err)
+			}
+			if got := (cell{g.crrntRow, g.crrntCol, g.direction}); got != tt.want {
+				t.Errorf("advance() from %v moved to %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetreat(t *testing.T) {
+	tests := []struct {
+		name string
+		from cell
+		want cell
+	}{
+		{name: "previous key of the word", from: cell{0, 2, config.Across}, want: cell{0, 1, config.Across}},
+		{name: "previous key up", from: cell{2, 0, config.Down}, want: cell{1, 0, config.Down}},
+		{name: "stays at the start of a word", from: cell{2, 0, config.Across}, want: cell{2, 0, config.Across}},
+		{name: "stays outside of a word", from: cell{1, 2, config.Across}, want: cell{1, 2, config.Across}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, newTestData(t), "alice")
+			g.crrntRow, g.crrntCol, g.direction = tt.from.row, tt.from.col, tt.from.dir
+
+			if err := g.retreat(); err != nil {
+				t.Fatal(err)
+			}
+			if got := (cell{g.crrntRow, g.crrntCol, g.direction}); got != tt.want {
+				t.Errorf("retreat() from %v moved to %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
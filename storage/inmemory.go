@@ -3,22 +3,31 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/amirkhaki/crossword/user"
+	"github.com/charmbracelet/ssh"
 )
 
 var Store Storage
 
 type UserNotFoundError error
 type GroupNotFoundError error
+type UserExistsError error
+type GroupExistsError error
+type GroupNotEmptyError error
 
 type inmemory struct {
+	mu     sync.RWMutex
 	users  []user.User
 	groups []user.Group
 }
 
 func (im *inmemory) AddUser(ctx context.Context, u user.User) error {
-	_, err := im.GetUser(ctx, u, func(u1, u2 user.User) bool {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	_, err := im.getUser(ctx, u, func(u1, u2 user.User) bool {
 		if u1.Username == u2.Username {
 			return true
 		}
@@ -28,7 +37,7 @@ func (im *inmemory) AddUser(ctx context.Context, u user.User) error {
 	err, ok := err.(UserNotFoundError)
 
 	if err == nil {
-		return fmt.Errorf("Adduser: user with given username (%s) exists!", u.Username)
+		return UserExistsError(fmt.Errorf("Adduser: user with given username (%s) exists!", u.Username))
 	} else if !ok {
 		return fmt.Errorf("Adduser: error while checking uniqueness: %w", err)
 	}
@@ -38,6 +47,12 @@ func (im *inmemory) AddUser(ctx context.Context, u user.User) error {
 }
 
 func (im *inmemory) GetUser(ctx context.Context, u user.User, equal func(user.User, user.User) bool) (user.User, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	return im.getUser(ctx, u, equal)
+}
+
+func (im *inmemory) getUser(ctx context.Context, u user.User, equal func(user.User, user.User) bool) (user.User, error) {
 	for _, v := range im.users {
 		if equal(v, u) {
 			return v, nil
@@ -46,9 +61,39 @@ func (im *inmemory) GetUser(ctx context.Context, u user.User, equal func(user.Us
 	return u, UserNotFoundError(fmt.Errorf("GetUser: user not found"))
 }
 
+func (im *inmemory) GetUserByUsername(ctx context.Context, username string) (user.User, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	for _, v := range im.users {
+		if v.Username == username {
+			return v, nil
+		}
+	}
+	return user.User{Username: username}, UserNotFoundError(fmt.Errorf("GetUserByUsername: user not found"))
+}
+
+// GetUserByKey returns the first user by username having key, like sqlite
+func (im *inmemory) GetUserByKey(ctx context.Context, key ssh.PublicKey) (user.User, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	found := false
+	var u user.User
+	for _, v := range im.users {
+		if v.HasKey(key) && (!found || v.Username < u.Username) {
+			u, found = v, true
+		}
+	}
+	if !found {
+		return u, UserNotFoundError(fmt.Errorf("GetUserByKey: user not found"))
+	}
+	return u, nil
+}
+
 func (im *inmemory) AddGroup(ctx context.Context, u user.Group) error {
-	_, err := im.GetGroup(ctx, u, func(u1, u2 user.Group) bool {
-		if u1.Name == u2.Name {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	_, err := im.getGroup(ctx, u, func(u1, u2 user.Group) bool {
+		if u1 == u2 {
 			return true
 		}
 		return false
@@ -57,9 +102,9 @@ func (im *inmemory) AddGroup(ctx context.Context, u user.Group) error {
 	err, ok := err.(GroupNotFoundError)
 
 	if err == nil {
-		return fmt.Errorf("Adduser: user with given username (%s) exists!", u.Name)
+		return GroupExistsError(fmt.Errorf("AddGroup: group with given name (%s) exists!", u.Name))
 	} else if !ok {
-		return fmt.Errorf("Adduser: error while checking uniqueness: %w", err)
+		return fmt.Errorf("AddGroup: error while checking uniqueness: %w", err)
 	}
 
 	im.groups = append(im.groups, u)
@@ -67,12 +112,102 @@ func (im *inmemory) AddGroup(ctx context.Context, u user.Group) error {
 }
 
 func (im *inmemory) GetGroup(ctx context.Context, u user.Group, equal func(user.Group, user.Group) bool) (user.Group, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	return im.getGroup(ctx, u, equal)
+}
+
+func (im *inmemory) getGroup(ctx context.Context, u user.Group, equal func(user.Group, user.Group) bool) (user.Group, error) {
 	for _, v := range im.groups {
 		if equal(v, u) {
 			return v, nil
 		}
 	}
-	return u, GroupNotFoundError(fmt.Errorf("GetGroup: user not found"))
+	return u, GroupNotFoundError(fmt.Errorf("GetGroup: group not found"))
+}
+
+func (im *inmemory) UpdateUser(ctx context.Context, u user.User) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	for i, v := range im.users {
+		if v.Username == u.Username {
+			im.users[i] = u
+			return nil
+		}
+	}
+	return UserNotFoundError(fmt.Errorf("UpdateUser: user not found"))
+}
+
+func (im *inmemory) DeleteUser(ctx context.Context, u user.User) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	for i, v := range im.users {
+		if v.Username == u.Username {
+			im.users = append(im.users[:i], im.users[i+1:]...)
+			return nil
+		}
+	}
+	return UserNotFoundError(fmt.Errorf("DeleteUser: user not found"))
+}
+
+func (im *inmemory) ListUsers(ctx context.Context, offset, limit int) ([]user.User, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	l := make([]user.User, len(im.users))
+	copy(l, im.users)
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Username < l[j].Username
+	})
+	start, end := paginate(len(l), offset, limit)
+	return l[start:end], nil
+}
+
+func (im *inmemory) ListUsersInGroup(ctx context.Context, grp user.Group, offset, limit int) ([]user.User, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	var l []user.User
+	for _, v := range im.users {
+		if v.Group == grp {
+			l = append(l, v)
+		}
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Username < l[j].Username
+	})
+	start, end := paginate(len(l), offset, limit)
+	return l[start:end], nil
+}
+
+func (im *inmemory) DeleteGroup(ctx context.Context, g user.Group) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	for _, v := range im.users {
+		if v.Group == g {
+			return GroupNotEmptyError(fmt.Errorf("DeleteGroup: group %s still has users", g.Name))
+		}
+	}
+	for i, v := range im.groups {
+		if v == g {
+			im.groups = append(im.groups[:i], im.groups[i+1:]...)
+			return nil
+		}
+	}
+	return GroupNotFoundError(fmt.Errorf("DeleteGroup: group not found"))
+}
+
+func (im *inmemory) ListGroups(ctx context.Context, offset, limit int) ([]user.Group, error) {
+	im.mu.RLock()
+	defer im.mu.RUnlock()
+	l := make([]user.Group, len(im.groups))
+	copy(l, im.groups)
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Contest != l[j].Contest {
+			return l[i].Contest < l[j].Contest
+		}
+		return l[i].Name < l[j].Name
+	})
+	start, end := paginate(len(l), offset, limit)
+	return l[start:end], nil
 }
 
 func NewInmemory() Storage {
@@ -0,0 +1,465 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/amirkhaki/crossword/user"
+	"github.com/charmbracelet/ssh"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order, index+1 is the schema version. never
+// edit an existing migration, append a new one instead
+var migrations = []string{
+	`CREATE TABLE groups (
+		name TEXT PRIMARY KEY
+	)`,
+	`CREATE TABLE users (
+		username   TEXT PRIMARY KEY,
+		password   TEXT NOT NULL,
+		group_name TEXT NOT NULL
+	)`,
+	`CREATE TABLE user_keys (
+		username TEXT NOT NULL,
+		key      TEXT NOT NULL,
+		PRIMARY KEY (username, key)
+	)`,
+	`ALTER TABLE users ADD COLUMN admin INTEGER NOT NULL DEFAULT 0`,
+	// groups are keyed by contest and name
+	`ALTER TABLE users ADD COLUMN group_contest TEXT NOT NULL DEFAULT '';
+	CREATE TABLE groups_new (
+		contest TEXT NOT NULL DEFAULT '',
+		name    TEXT NOT NULL,
+		PRIMARY KEY (contest, name)
+	);
+	INSERT INTO groups_new (name) SELECT name FROM groups;
+	DROP TABLE groups;
+	ALTER TABLE groups_new RENAME TO groups`,
+	// keys are looked up by blob, it is empty for invalid keys
+	`ALTER TABLE user_keys ADD COLUMN blob TEXT NOT NULL DEFAULT '';
+	CREATE INDEX user_keys_blob ON user_keys (blob)`,
+}
+
+type sqlite struct {
+	db *sql.DB
+}
+
+func (s *sqlite) migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY
+	)`)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	var version int
+	err = s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate: version %d: %w", i+1, err)
+		}
+		if _, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, i+1); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migrate: version %d: %w", i+1, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("migrate: version %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// fillBlobs sets blob of keys stored before it existed, sql can't parse keys
+func (s *sqlite) fillBlobs(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT username, key FROM user_keys WHERE blob = ''`)
+	if err != nil {
+		return err
+	}
+	var l [][2]string
+	for rows.Next() {
+		var username, key string
+		if err = rows.Scan(&username, &key); err != nil {
+			rows.Close()
+			return err
+		}
+		l = append(l, [2]string{username, key})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, k := range l {
+		blob := authorizedKeyBlob(k[1])
+		if blob == "" {
+			continue
+		}
+		_, err = s.db.ExecContext(ctx, `UPDATE user_keys SET blob = ? WHERE username = ? AND key = ?`, blob, k[0], k[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyBlob returns wire format of key, as it is written in authorized_keys
+func keyBlob(key ssh.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key.Marshal())
+}
+
+// authorizedKeyBlob returns blob of key in authorized_keys format, or empty
+// string if it is invalid
+func authorizedKeyBlob(key string) string {
+	k, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return ""
+	}
+	return keyBlob(k)
+}
+
+// scanUsers returns users of query without their keys
+func (s *sqlite) scanUsers(ctx context.Context, query string, args ...interface{}) ([]user.User, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var l []user.User
+	for rows.Next() {
+		var u user.User
+		if err = rows.Scan(&u.Username, &u.Password, &u.Group.Contest, &u.Group.Name, &u.Admin); err != nil {
+			return nil, err
+		}
+		l = append(l, u)
+	}
+	return l, rows.Err()
+}
+
+func (s *sqlite) queryUsers(ctx context.Context, query string, args ...interface{}) ([]user.User, error) {
+	l, err := s.scanUsers(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range l {
+		l[i].AuthorizedKeys = keys[l[i].Username]
+	}
+	return l, nil
+}
+
+// queryUser returns the first user of query, loading keys of that user
+// only. sql.ErrNoRows is returned if query has no rows
+func (s *sqlite) queryUser(ctx context.Context, query string, args ...interface{}) (user.User, error) {
+	l, err := s.scanUsers(ctx, query, args...)
+	if err != nil {
+		return user.User{}, err
+	}
+	if len(l) == 0 {
+		return user.User{}, sql.ErrNoRows
+	}
+	u := l[0]
+	rows, err := s.db.QueryContext(ctx, `SELECT key FROM user_keys WHERE username = ? ORDER BY key`, u.Username)
+	if err != nil {
+		return u, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return u, err
+		}
+		u.AuthorizedKeys = append(u.AuthorizedKeys, key)
+	}
+	return u, rows.Err()
+}
+
+// keys returns authorized keys of every user keyed by username
+func (s *sqlite) keys(ctx context.Context) (map[string][]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT username, key FROM user_keys ORDER BY username, key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := make(map[string][]string)
+	for rows.Next() {
+		var username, key string
+		if err = rows.Scan(&username, &key); err != nil {
+			return nil, err
+		}
+		keys[username] = append(keys[username], key)
+	}
+	return keys, rows.Err()
+}
+
+// setKeys replaces authorized keys of u
+func setKeys(ctx context.Context, tx *sql.Tx, u user.User) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_keys WHERE username = ?`, u.Username); err != nil {
+		return err
+	}
+	for _, key := range u.AuthorizedKeys {
+		_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO user_keys (username, key, blob) VALUES (?, ?, ?)`,
+			u.Username, key, authorizedKeyBlob(key))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqlite) queryGroups(ctx context.Context, query string, args ...interface{}) ([]user.Group, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var l []user.Group
+	for rows.Next() {
+		var g user.Group
+		if err = rows.Scan(&g.Contest, &g.Name); err != nil {
+			return nil, err
+		}
+		l = append(l, g)
+	}
+	return l, rows.Err()
+}
+
+// sqlLimit converts limit of storage interface to sqlite, where -1 means no limit
+func sqlLimit(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
+func (s *sqlite) AddUser(ctx context.Context, u user.User) error {
+	_, err := s.GetUserByUsername(ctx, u.Username)
+
+	err, ok := err.(UserNotFoundError)
+
+	if err == nil {
+		return UserExistsError(fmt.Errorf("Adduser: user with given username (%s) exists!", u.Username))
+	} else if !ok {
+		return fmt.Errorf("Adduser: error while checking uniqueness: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("AddUser: %w", err)
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `INSERT INTO users (username, password, group_contest, group_name, admin) VALUES (?, ?, ?, ?, ?)`,
+		u.Username, u.Password, u.Group.Contest, u.Group.Name, u.Admin)
+	if err != nil {
+		return fmt.Errorf("AddUser: %w", err)
+	}
+	if err = setKeys(ctx, tx, u); err != nil {
+		return fmt.Errorf("AddUser: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("AddUser: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlite) GetUser(ctx context.Context, u user.User, equal func(user.User, user.User) bool) (user.User, error) {
+	users, err := s.queryUsers(ctx, `SELECT username, password, group_contest, group_name, admin FROM users ORDER BY username`)
+	if err != nil {
+		return u, fmt.Errorf("GetUser: %w", err)
+	}
+	for _, v := range users {
+		if equal(v, u) {
+			return v, nil
+		}
+	}
+	return u, UserNotFoundError(fmt.Errorf("GetUser: user not found"))
+}
+
+func (s *sqlite) GetUserByUsername(ctx context.Context, username string) (user.User, error) {
+	u, err := s.queryUser(ctx, `SELECT username, password, group_contest, group_name, admin FROM users
+		WHERE username = ?`, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.User{Username: username}, UserNotFoundError(fmt.Errorf("GetUserByUsername: user not found"))
+	}
+	if err != nil {
+		return u, fmt.Errorf("GetUserByUsername: %w", err)
+	}
+	return u, nil
+}
+
+func (s *sqlite) GetUserByKey(ctx context.Context, key ssh.PublicKey) (user.User, error) {
+	u, err := s.queryUser(ctx, `SELECT u.username, u.password, u.group_contest, u.group_name, u.admin FROM users u
+		JOIN user_keys k ON k.username = u.username WHERE k.blob = ? ORDER BY u.username LIMIT 1`, keyBlob(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return u, UserNotFoundError(fmt.Errorf("GetUserByKey: user not found"))
+	}
+	if err != nil {
+		return u, fmt.Errorf("GetUserByKey: %w", err)
+	}
+	return u, nil
+}
+
+func (s *sqlite) AddGroup(ctx context.Context, g user.Group) error {
+	_, err := s.GetGroup(ctx, g, func(g1, g2 user.Group) bool {
+		return g1 == g2
+	})
+
+	err, ok := err.(GroupNotFoundError)
+
+	if err == nil {
+		return GroupExistsError(fmt.Errorf("AddGroup: group with given name (%s) exists!", g.Name))
+	} else if !ok {
+		return fmt.Errorf("AddGroup: error while checking uniqueness: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO groups (contest, name) VALUES (?, ?)`, g.Contest, g.Name)
+	if err != nil {
+		return fmt.Errorf("AddGroup: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlite) GetGroup(ctx context.Context, g user.Group, equal func(user.Group, user.Group) bool) (user.Group, error) {
+	groups, err := s.queryGroups(ctx, `SELECT contest, name FROM groups ORDER BY contest, name`)
+	if err != nil {
+		return g, fmt.Errorf("GetGroup: %w", err)
+	}
+	for _, v := range groups {
+		if equal(v, g) {
+			return v, nil
+		}
+	}
+	return g, GroupNotFoundError(fmt.Errorf("GetGroup: group not found"))
+}
+
+func (s *sqlite) UpdateUser(ctx context.Context, u user.User) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("UpdateUser: %w", err)
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `UPDATE users SET password = ?, group_contest = ?, group_name = ?, admin = ? WHERE username = ?`,
+		u.Password, u.Group.Contest, u.Group.Name, u.Admin, u.Username)
+	if err != nil {
+		return fmt.Errorf("UpdateUser: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("UpdateUser: %w", err)
+	} else if n == 0 {
+		return UserNotFoundError(fmt.Errorf("UpdateUser: user not found"))
+	}
+	if err = setKeys(ctx, tx, u); err != nil {
+		return fmt.Errorf("UpdateUser: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("UpdateUser: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlite) DeleteUser(ctx context.Context, u user.User) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("DeleteUser: %w", err)
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `DELETE FROM users WHERE username = ?`, u.Username)
+	if err != nil {
+		return fmt.Errorf("DeleteUser: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("DeleteUser: %w", err)
+	} else if n == 0 {
+		return UserNotFoundError(fmt.Errorf("DeleteUser: user not found"))
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_keys WHERE username = ?`, u.Username); err != nil {
+		return fmt.Errorf("DeleteUser: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("DeleteUser: %w", err)
+	}
+	return nil
+}
+
+func (s *sqlite) ListUsers(ctx context.Context, offset, limit int) ([]user.User, error) {
+	l, err := s.queryUsers(ctx, `SELECT username, password, group_contest, group_name, admin FROM users
+		ORDER BY username LIMIT ? OFFSET ?`, sqlLimit(limit), offset)
+	if err != nil {
+		return nil, fmt.Errorf("ListUsers: %w", err)
+	}
+	return l, nil
+}
+
+func (s *sqlite) ListUsersInGroup(ctx context.Context, grp user.Group, offset, limit int) ([]user.User, error) {
+	l, err := s.queryUsers(ctx, `SELECT username, password, group_contest, group_name, admin FROM users
+		WHERE group_contest = ? AND group_name = ? ORDER BY username LIMIT ? OFFSET ?`, grp.Contest, grp.Name, sqlLimit(limit), offset)
+	if err != nil {
+		return nil, fmt.Errorf("ListUsersInGroup: %w", err)
+	}
+	return l, nil
+}
+
+func (s *sqlite) DeleteGroup(ctx context.Context, g user.Group) error {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE group_contest = ? AND group_name = ?`,
+		g.Contest, g.Name).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("DeleteGroup: %w", err)
+	}
+	if count != 0 {
+		return GroupNotEmptyError(fmt.Errorf("DeleteGroup: group %s still has users", g.Name))
+	}
+	res, err := s.db.ExecContext(ctx, `DELETE FROM groups WHERE contest = ? AND name = ?`, g.Contest, g.Name)
+	if err != nil {
+		return fmt.Errorf("DeleteGroup: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("DeleteGroup: %w", err)
+	} else if n == 0 {
+		return GroupNotFoundError(fmt.Errorf("DeleteGroup: group not found"))
+	}
+	return nil
+}
+
+func (s *sqlite) ListGroups(ctx context.Context, offset, limit int) ([]user.Group, error) {
+	l, err := s.queryGroups(ctx, `SELECT contest, name FROM groups ORDER BY contest, name LIMIT ? OFFSET ?`, sqlLimit(limit), offset)
+	if err != nil {
+		return nil, fmt.Errorf("ListGroups: %w", err)
+	}
+	return l, nil
+}
+
+// NewSqlite opens sqlite database at path, creating it if needed, and
+// brings its schema up to date
+func NewSqlite(path string) (Storage, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("NewSqlite: %w", err)
+	}
+	// sqlite allows a single writer, serialize access instead of failing
+	// with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	s := sqlite{db: db}
+	if err = s.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("NewSqlite: %w", err)
+	}
+	if err = s.fillBlobs(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("NewSqlite: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/amirkhaki/crossword/user"
+)
+
+func TestSqliteMigrate(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		// from is the schema version the database is left at before it is
+		// opened, -1 opens a new database
+		from int
+		// setup fills the database at version from
+		setup []string
+	}{
+		{name: "new database", from: -1},
+		{name: "up to date", from: len(migrations)},
+		{
+			name: "before contests",
+			from: 4,
+			setup: []string{
+				`INSERT INTO groups (name) VALUES ('red')`,
+				`INSERT INTO users (username, password, group_name, admin) VALUES ('alice', 'hash', 'red', 0)`,
+				`INSERT INTO user_keys (username, key) VALUES ('alice', 'ssh-ed25519 AAAA')`,
+			},
+		},
+		{
+			name: "before admins",
+			from: 3,
+			setup: []string{
+				`INSERT INTO groups (name) VALUES ('red')`,
+				`INSERT INTO users (username, password, group_name) VALUES ('alice', 'hash', 'red')`,
+				`INSERT INTO user_keys (username, key) VALUES ('alice', 'ssh-ed25519 AAAA')`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "crossword.db")
+			if tt.from >= 0 {
+				db, err := sql.Open("sqlite", path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				old := migrations
+				migrations = migrations[:tt.from]
+				err = (&sqlite{db: db}).migrate(ctx)
+				migrations = old
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, q := range tt.setup {
+					if _, err = db.Exec(q); err != nil {
+						t.Fatal(err)
+					}
+				}
+				db.Close()
+			}
+
+			s, err := NewSqlite(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var version int
+			err = s.(*sqlite).db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version != len(migrations) {
+				t.Errorf("version = %d, want %d", version, len(migrations))
+			}
+			if len(tt.setup) == 0 {
+				return
+			}
+			u, err := s.GetUser(ctx, user.User{Username: "alice"}, func(u1, u2 user.User) bool {
+				return u1.Username == u2.Username
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := user.Group{Name: "red"}
+			if u.Group != want || u.Password != "hash" || u.Admin || len(u.AuthorizedKeys) != 1 {
+				t.Errorf("user = %+v after migration", u)
+			}
+			groups, err := s.ListGroups(ctx, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(groups) != 1 || groups[0] != want {
+				t.Errorf("groups = %v, want [%v]", groups, want)
+			}
+			// contest is part of the key after migration
+			if err = s.AddGroup(ctx, user.Group{Contest: "finals", Name: "red"}); err != nil {
+				t.Errorf("AddGroup in another contest: %v", err)
+			}
+		})
+	}
+}
+
+func TestSqliteFillBlobs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "crossword.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := migrations
+	migrations = migrations[:5]
+	err = (&sqlite{db: db}).migrate(ctx)
+	migrations = old
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, line := newKey(t, "alice@laptop")
+	for _, q := range []string{
+		`INSERT INTO users (username, password, group_name) VALUES ('alice', 'hash', 'red')`,
+		`INSERT INTO user_keys (username, key) VALUES ('alice', 'ssh-ed25519 AAAA')`,
+		`INSERT INTO user_keys (username, key) VALUES ('alice', '` + line + `')`,
+	} {
+		if _, err = db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	s, err := NewSqlite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, err := s.GetUserByKey(ctx, key); err != nil || u.Username != "alice" {
+		t.Errorf("GetUserByKey() of a key stored before blobs = %+v, %v", u, err)
+	}
+}
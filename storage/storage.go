@@ -2,23 +2,35 @@ package storage
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/amirkhaki/crossword/config"
 	"github.com/amirkhaki/crossword/user"
+	"github.com/charmbracelet/ssh"
 )
 
 type User interface {
 	AddUser(context.Context, user.User) error
 	GetUser(context.Context, user.User, func(user.User, user.User) bool) (user.User, error)
-	// TODO UpdateUser(context.Context, user.User) error
-	// TODO DeleteUser(context.Context, user.User) error
+	GetUserByUsername(ctx context.Context, username string) (user.User, error)
+	// GetUserByKey returns user having key as one of its authorized keys,
+	// the first one by username if several have it
+	GetUserByKey(ctx context.Context, key ssh.PublicKey) (user.User, error)
+	// UpdateUser replaces user having the same username
+	UpdateUser(context.Context, user.User) error
+	DeleteUser(context.Context, user.User) error
+	// ListUsers returns users ordered by username, skipping offset users and
+	// returning at most limit of them. limit <= 0 means no limit
+	ListUsers(ctx context.Context, offset, limit int) ([]user.User, error)
+	ListUsersInGroup(ctx context.Context, grp user.Group, offset, limit int) ([]user.User, error)
 }
 
 type Group interface {
 	AddGroup(context.Context, user.Group) error
 	GetGroup(context.Context, user.Group, func(user.Group, user.Group) bool) (user.Group, error)
-	// TODO UpdateGroup(context.Context, user.Group) error
-	// TODO DeleteGroup(context.Context, user.Group) error
+	// DeleteGroup fails with GroupNotEmptyError if group still has users
+	DeleteGroup(context.Context, user.Group) error
+	ListGroups(ctx context.Context, offset, limit int) ([]user.Group, error)
 }
 
 type Storage interface {
@@ -26,6 +38,48 @@ type Storage interface {
 	Group
 }
 
+// NewStorage returns the backend selected in storage section of cfg,
+// inmemory is used if no driver is given
 func NewStorage(cfg config.Config) (Storage, error) {
-	return nil, nil
+	switch cfg.Storage.Driver {
+	case "", "inmemory":
+		return NewInmemory(), nil
+	case "sqlite":
+		return NewSqlite(cfg.Storage.Path)
+	}
+	return nil, fmt.Errorf("NewStorage: unknown driver %q", cfg.Storage.Driver)
+}
+
+// dummyHash is checked when user is not found, so that unknown usernames
+// take as long as wrong passwords
+const dummyHash = "$2a$10$JGTZ6lSrP2JD6m/l8Si9e.A9K339awu1BKvpbva6q.KWMNuqMcWeK"
+
+// Authenticate returns user with given username if password matches its
+// hash. UserNotFoundError is returned for unknown usernames and wrong
+// passwords alike
+func Authenticate(ctx context.Context, s User, username, password string) (user.User, error) {
+	u, err := s.GetUserByUsername(ctx, username)
+	if err != nil {
+		user.User{Password: dummyHash}.CheckPassword(password)
+		return u, err
+	}
+	if !u.CheckPassword(password) {
+		return user.User{Username: username}, UserNotFoundError(fmt.Errorf("Authenticate: invalid password"))
+	}
+	return u, nil
+}
+
+// paginate returns bounds of the page of a list with length n
+func paginate(n, offset, limit int) (start, end int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > n {
+		offset = n
+	}
+	end = n
+	if limit > 0 && offset+limit < n {
+		end = offset + limit
+	}
+	return offset, end
 }
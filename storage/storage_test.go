@@ -0,0 +1,294 @@
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/amirkhaki/crossword/user"
+	"github.com/charmbracelet/ssh"
+	"golang.org/x/crypto/bcrypt"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// backends returns a new empty storage of every driver
+func backends(t *testing.T) map[string]Storage {
+	s, err := NewSqlite(filepath.Join(t.TempDir(), "crossword.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Storage{"inmemory": NewInmemory(), "sqlite": s}
+}
+
+func usernames(l []user.User) []string {
+	names := []string{}
+	for _, u := range l {
+		names = append(names, u.Username)
+	}
+	return names
+}
+
+func byUsername(u1, u2 user.User) bool {
+	return u1.Username == u2.Username
+}
+
+func TestStorageUsers(t *testing.T) {
+	ctx := context.Background()
+	red := user.Group{Name: "red"}
+	blue := user.Group{Contest: "finals", Name: "blue"}
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, u := range []user.User{
+				{Username: "carol", Password: "c", Group: blue},
+				{Username: "alice", Password: "a", Group: red, AuthorizedKeys: []string{"ssh-ed25519 AAAA"}},
+				{Username: "bob", Password: "b", Group: red},
+				{Username: "root", Password: "r", Admin: true},
+			} {
+				if err := s.AddUser(ctx, u); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.AddUser(ctx, user.User{Username: "bob"}); err == nil {
+				t.Error("AddUser of an existing username succeeded")
+			}
+
+			u, err := s.GetUser(ctx, user.User{Username: "alice"}, byUsername)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := user.User{Username: "alice", Password: "a", Group: red, AuthorizedKeys: []string{"ssh-ed25519 AAAA"}}
+			if !reflect.DeepEqual(u, want) {
+				t.Errorf("GetUser = %+v, want %+v", u, want)
+			}
+			if _, err = s.GetUser(ctx, user.User{Username: "dave"}, byUsername); err == nil {
+				t.Error("GetUser of an unknown username succeeded")
+			}
+
+			want = user.User{Username: "alice", Password: "a2", Group: blue, Admin: true}
+			if err = s.UpdateUser(ctx, want); err != nil {
+				t.Fatal(err)
+			}
+			if u, _ = s.GetUser(ctx, want, byUsername); !reflect.DeepEqual(u, want) {
+				t.Errorf("GetUser after UpdateUser = %+v, want %+v", u, want)
+			}
+			if err = s.UpdateUser(ctx, user.User{Username: "dave"}); err == nil {
+				t.Error("UpdateUser of an unknown username succeeded")
+			}
+
+			if err = s.DeleteUser(ctx, user.User{Username: "root"}); err != nil {
+				t.Fatal(err)
+			}
+			if err = s.DeleteUser(ctx, user.User{Username: "root"}); err == nil {
+				t.Error("DeleteUser of a deleted user succeeded")
+			}
+
+			tests := []struct {
+				name          string
+				grp           *user.Group
+				offset, limit int
+				want          []string
+			}{
+				{name: "all", want: []string{"alice", "bob", "carol"}},
+				{name: "first page", limit: 2, want: []string{"alice", "bob"}},
+				{name: "second page", offset: 2, limit: 2, want: []string{"carol"}},
+				{name: "past the end", offset: 5, want: []string{}},
+				{name: "group", grp: &blue, want: []string{"alice", "carol"}},
+				{name: "group page", grp: &blue, offset: 1, limit: 1, want: []string{"carol"}},
+				{name: "same name in another contest", grp: &user.Group{Name: "blue"}, want: []string{}},
+			}
+			for _, tt := range tests {
+				var l []user.User
+				if tt.grp == nil {
+					l, err = s.ListUsers(ctx, tt.offset, tt.limit)
+				} else {
+					l, err = s.ListUsersInGroup(ctx, *tt.grp, tt.offset, tt.limit)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := usernames(l); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// newKey returns a random public key and its line in authorized_keys
+func newKey(t *testing.T, comment string) (ssh.PublicKey, string) {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := gossh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k, strings.TrimSpace(string(gossh.MarshalAuthorizedKey(k))) + " " + comment
+}
+
+func TestStorageUserLookup(t *testing.T) {
+	ctx := context.Background()
+	shared, sharedLine := newKey(t, "shared@laptop")
+	own, ownLine := newKey(t, "bob@desktop")
+	unknown, _ := newKey(t, "")
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, u := range []user.User{
+				{Username: "bob", Password: "b", AuthorizedKeys: []string{sharedLine, ownLine}},
+				{Username: "alice", Password: "a", AuthorizedKeys: []string{"ssh-ed25519 AAAA", sharedLine}},
+				{Username: "carol", Password: "c"},
+			} {
+				if err := s.AddUser(ctx, u); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			u, err := s.GetUserByUsername(ctx, "bob")
+			if want := []string{sharedLine, ownLine}; err != nil || u.Username != "bob" || len(u.AuthorizedKeys) != len(want) {
+				t.Errorf("GetUserByUsername(bob) = %+v, %v", u, err)
+			}
+			if _, err = s.GetUserByUsername(ctx, "dave"); err == nil {
+				t.Error("GetUserByUsername of an unknown username succeeded")
+			}
+
+			tests := []struct {
+				name string
+				key  ssh.PublicKey
+				want string
+			}{
+				{name: "key of several users", key: shared, want: "alice"},
+				{name: "key of one user", key: own, want: "bob"},
+				{name: "unknown key", key: unknown},
+			}
+			for _, tt := range tests {
+				u, err := s.GetUserByKey(ctx, tt.key)
+				if tt.want == "" {
+					if err == nil {
+						t.Errorf("%s: GetUserByKey() = %s, want error", tt.name, u.Username)
+					}
+					continue
+				}
+				if err != nil || u.Username != tt.want {
+					t.Errorf("%s: GetUserByKey() = %s, %v, want %s", tt.name, u.Username, err, tt.want)
+				}
+			}
+
+			if err = s.UpdateUser(ctx, user.User{Username: "bob", Password: "b"}); err != nil {
+				t.Fatal(err)
+			}
+			if u, err = s.GetUserByKey(ctx, own); err == nil {
+				t.Errorf("GetUserByKey() of a removed key = %s", u.Username)
+			}
+		})
+	}
+}
+
+func TestStorageGroups(t *testing.T) {
+	ctx := context.Background()
+	red := user.Group{Name: "red"}
+	finalsRed := user.Group{Contest: "finals", Name: "red"}
+	blue := user.Group{Name: "blue"}
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, g := range []user.Group{red, finalsRed, blue} {
+				if err := s.AddGroup(ctx, g); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.AddGroup(ctx, red); err == nil {
+				t.Error("AddGroup of an existing group succeeded")
+			}
+
+			if err := s.AddUser(ctx, user.User{Username: "alice", Group: red}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DeleteGroup(ctx, red); err == nil {
+				t.Error("DeleteGroup of a group with users succeeded")
+			}
+			if err := s.DeleteGroup(ctx, finalsRed); err != nil {
+				t.Error(err)
+			}
+			if err := s.DeleteGroup(ctx, finalsRed); err == nil {
+				t.Error("DeleteGroup of a deleted group succeeded")
+			}
+
+			tests := []struct {
+				offset, limit int
+				want          []user.Group
+			}{
+				{want: []user.Group{blue, red}},
+				{limit: 1, want: []user.Group{blue}},
+				{offset: 1, limit: 1, want: []user.Group{red}},
+				{offset: 2},
+			}
+			for _, tt := range tests {
+				l, err := s.ListGroups(ctx, tt.offset, tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(l) != len(tt.want) || (len(l) != 0 && !reflect.DeepEqual(l, tt.want)) {
+					t.Errorf("ListGroups(%d, %d) = %v, want %v", tt.offset, tt.limit, l, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	hashed, err := user.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an unknown username is checked against dummyHash, at the cost of real
+	// hashes so it takes as long as a wrong password
+	if cost, err := bcrypt.Cost([]byte(dummyHash)); err != nil || cost != bcrypt.DefaultCost {
+		t.Errorf("cost of dummyHash = %d, %v, want %d", cost, err, bcrypt.DefaultCost)
+	}
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  bool
+	}{
+		{name: "correct password", username: "alice", password: "secret"},
+		{name: "wrong password", username: "alice", password: "Secret", wantErr: true},
+		{name: "empty password", username: "alice", wantErr: true},
+		{name: "unknown user", username: "dave", password: "secret", wantErr: true},
+		{name: "hashed in config", username: "bob", password: "open sesame"},
+		{name: "hash as password", username: "bob", password: "$2a$04$yRWdDwpHQGmuQdahrgnw/eqUcd/nmHRMVyXe48lGq29n1iaB/Gsqi", wantErr: true},
+	}
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, u := range []user.User{
+				{Username: "alice", Password: hashed, Group: user.Group{Name: "red"}},
+				{Username: "bob", Password: "$2a$04$yRWdDwpHQGmuQdahrgnw/eqUcd/nmHRMVyXe48lGq29n1iaB/Gsqi"},
+			} {
+				if err := s.AddUser(ctx, u); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, tt := range tests {
+				u, err := Authenticate(ctx, s, tt.username, tt.password)
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("%s: Authenticate() succeeded", tt.name)
+					}
+					if u.Password != "" {
+						t.Errorf("%s: Authenticate() returned hash of %s", tt.name, tt.username)
+					}
+					continue
+				}
+				if err != nil || u.Username != tt.username {
+					t.Errorf("%s: Authenticate() = %s, %v, want %s", tt.name, u.Username, err, tt.username)
+				}
+			}
+		})
+	}
+}
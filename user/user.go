@@ -1,13 +1,27 @@
 package user
 
+import (
+	"github.com/charmbracelet/ssh"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Group is identified by its contest and name together, groups with the
+// same name in different contests are unrelated
 type Group struct {
-	Name string
+	Contest string `json:"contest"`
+	Name    string `json:"name"`
 }
 
 type User struct {
-	Username string
-	Password string
-	Group    Group
+	Username string `json:"username"`
+	// Password is a bcrypt hash of the password
+	Password string `json:"password"`
+	Group    Group  `json:"group"`
+	// AuthorizedKeys are ssh public keys in authorized_keys format, users
+	// connecting with one of them skip the login form
+	AuthorizedKeys []string `json:"authorized_keys"`
+	// Admin users get the admin panel instead of a game
+	Admin bool `json:"admin"`
 }
 
 func NewGroup(name string) Group {
@@ -17,3 +31,39 @@ func NewGroup(name string) Group {
 func NewUser(username, password string, grp Group) User {
 	return User{Username: username, Password: password, Group: grp}
 }
+
+// HashPassword returns bcrypt hash of password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// IsHashed reports whether password is already a bcrypt hash
+func IsHashed(password string) bool {
+	_, err := bcrypt.Cost([]byte(password))
+	return err == nil
+}
+
+// CheckPassword reports whether password matches hash of u, comparison is
+// done in constant time
+func (u User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil
+}
+
+// HasKey reports whether key is one of authorized keys of u, invalid
+// authorized keys are ignored
+func (u User) HasKey(key ssh.PublicKey) bool {
+	for _, k := range u.AuthorizedKeys {
+		authorized, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k))
+		if err != nil {
+			continue
+		}
+		if ssh.KeysEqual(authorized, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package user
+
+import "testing"
+
+// openSesame is a hash of "open sesame" as written in a config file
+const openSesame = "$2a$04$yRWdDwpHQGmuQdahrgnw/eqUcd/nmHRMVyXe48lGq29n1iaB/Gsqi"
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == "secret" || !IsHashed(hash) {
+		t.Errorf("HashPassword() = %q, want a bcrypt hash", hash)
+	}
+	if again, _ := HashPassword("secret"); again == hash {
+		t.Error("HashPassword() of the same password twice returned the same salt")
+	}
+}
+
+func TestIsHashed(t *testing.T) {
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: openSesame, want: true},
+		{password: "open sesame"},
+		{password: ""},
+		{password: "$2a$04$tooshort"},
+	}
+	for _, tt := range tests {
+		if got := IsHashed(tt.password); got != tt.want {
+			t.Errorf("IsHashed(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hashed, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{name: "correct", hash: hashed, password: "secret", want: true},
+		{name: "wrong", hash: hashed, password: "Secret"},
+		{name: "empty", hash: hashed, password: ""},
+		{name: "hashed in config", hash: openSesame, password: "open sesame", want: true},
+		{name: "plain text is never accepted", hash: "secret", password: "secret"},
+	}
+	for _, tt := range tests {
+		if got := (User{Password: tt.hash}).CheckPassword(tt.password); got != tt.want {
+			t.Errorf("%s: CheckPassword(%q) = %v, want %v", tt.name, tt.password, got, tt.want)
+		}
+	}
+}